@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/canefe/pretty-go-log/logrus/pretty"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	// The namespace is shown as a column between the level and the tag
+	log := pretty.New(
+		pretty.WithLevel(logrus.InfoLevel),
+		pretty.WithNamespace("Main"),
+	)
+
+	// Children share the console and files but keep their own level
+	auth := pretty.Child(log, "Auth")
+	token := pretty.Child(auth, "Token")
+	token.SetLevel(logrus.DebugLevel)
+
+	log.Info("[Server] Listening on :8080")
+	auth.Info("[Login] User alice signed in")
+	token.Debug("[JWT] Refreshed token")
+	auth.Debug("[Login] Hidden, Main/Auth is still at Info")
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+
+	"github.com/canefe/pretty-go-log/logrus/pretty"
+)
+
+func main() {
+	// Render slog records with the same layout as the logrus formatter
+	handler := pretty.NewSlogHandler(os.Stdout, pretty.NewCustomFormatter(), &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})
+	log := slog.New(handler)
+
+	log.Debug("[Init] Starting")
+	log.Info("[Server] Listening", "port", 8080)
+
+	// Groups are flattened into dotted keys: req.method=GET req.path=/api/users
+	reqLog := log.WithGroup("req").With("method", "GET")
+	reqLog.Info("[HTTP] Request completed", "path", "/api/users", "status", 200)
+
+	log.Error("[DB] Connection lost", "retry", true)
+}
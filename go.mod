@@ -3,8 +3,13 @@ module github.com/canefe/pretty-go-log
 go 1.24
 
 require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/mattn/go-runewidth v0.0.16
+	github.com/rivo/uniseg v0.2.0
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/term v0.20.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+require golang.org/x/sys v0.20.0 // indirect
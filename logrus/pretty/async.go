@@ -0,0 +1,285 @@
+package pretty
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultQueueSize is the number of entries buffered per output when
+// AsyncConfig.QueueSize is 0
+const DefaultQueueSize = 1024
+
+// QueuePolicy defines what happens to an entry when an output's queue is full
+type QueuePolicy int
+
+const (
+	QueueBlock      QueuePolicy = iota // Wait for room, never drop
+	QueueDropNewest                    // Drop the entry being logged
+	QueueDropOldest                    // Drop the oldest queued entry to make room
+	QueueDropBelow                     // Drop entries less severe than DropLevel, wait for the others
+)
+
+// AsyncConfig configures asynchronous writing, see WithAsync
+type AsyncConfig struct {
+	// QueueSize is the number of entries buffered per output. 0 uses DefaultQueueSize.
+	QueueSize int
+	Policy    QueuePolicy
+	// DropLevel is used by QueueDropBelow, e.g. InfoLevel drops Debug and Trace
+	// entries while the queue is full
+	DropLevel logrus.Level
+}
+
+// WithAsync writes entries in the background instead of inside the logging
+// call. Entries are still formatted by the caller, then queued; each output
+// (sink, console or file) has its own queue and worker, and writes its
+// entries in the order they were logged.
+//
+// Fatal and Panic entries wait until every queue is written, so they are not
+// lost when the program exits.
+//
+//	log := pretty.New(pretty.WithAsync(pretty.AsyncConfig{
+//	    QueueSize: 4096,
+//	    Policy:    pretty.QueueDropBelow,
+//	    DropLevel: logrus.InfoLevel,
+//	}))
+func WithAsync(cfg AsyncConfig) Option {
+	return func(c *Config) {
+		c.Async = &cfg
+	}
+}
+
+// Dropped returns the number of entries l's outputs dropped because their
+// queue was full. Use Sink.Dropped for a single sink.
+func Dropped(l *logrus.Logger) uint64 {
+	mw := multiWriterOf(l)
+	if mw == nil {
+		return 0
+	}
+	var n uint64
+	for _, p := range mw.pairs {
+		if p.q != nil {
+			n += p.q.dropped.Load()
+		}
+	}
+	return n
+}
+
+// asyncQueue is a bounded FIFO of formatted entries with one worker writing
+// them to w
+type asyncQueue struct {
+	w         io.Writer
+	size      int
+	policy    QueuePolicy
+	dropLevel logrus.Level
+	dropped   *atomic.Uint64
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	drained  *sync.Cond // Broadcast when the queue is empty and nothing is being written
+	items    [][]byte   // Formatted entries, oldest first
+	busy     bool       // The worker is writing an item
+	closed   bool
+
+	done chan struct{} // Closed when the worker exits
+}
+
+// newAsyncQueue starts a worker writing to w. dropped counts the entries the
+// policy drops; nil gives the queue its own counter.
+func newAsyncQueue(w io.Writer, cfg AsyncConfig, dropped *atomic.Uint64) *asyncQueue {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if dropped == nil {
+		dropped = new(atomic.Uint64)
+	}
+	q := &asyncQueue{
+		w:         w,
+		size:      cfg.QueueSize,
+		policy:    cfg.Policy,
+		dropLevel: cfg.DropLevel,
+		dropped:   dropped,
+		done:      make(chan struct{}),
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	q.drained = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		for len(q.items) == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if len(q.items) == 0 {
+			return // Closed and drained
+		}
+		buf := q.pop()
+		q.busy = true
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		if _, err := q.w.Write(buf); err != nil {
+			fmt.Fprintf(os.Stderr, "log write err: %v\n", err)
+		}
+
+		q.mu.Lock()
+		q.busy = false
+		if len(q.items) == 0 {
+			q.drained.Broadcast()
+		}
+	}
+}
+
+// pop removes the oldest item; q.mu must be held
+func (q *asyncQueue) pop() []byte {
+	buf := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	return buf
+}
+
+// enqueue queues buf, applying the policy when the queue is full. After
+// close, buf is written directly once the worker has finished.
+func (q *asyncQueue) enqueue(buf []byte, level logrus.Level) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		<-q.done
+		if _, err := q.w.Write(buf); err != nil {
+			fmt.Fprintf(os.Stderr, "log write err: %v\n", err)
+		}
+		return
+	}
+
+	for len(q.items) >= q.size {
+		switch {
+		case q.policy == QueueDropNewest, q.policy == QueueDropBelow && level > q.dropLevel:
+			q.dropped.Add(1)
+			q.mu.Unlock()
+			return
+		case q.policy == QueueDropOldest:
+			q.pop()
+			q.dropped.Add(1)
+		default:
+			q.notFull.Wait()
+		}
+	}
+	q.items = append(q.items, buf)
+	q.notEmpty.Signal()
+	q.mu.Unlock()
+}
+
+// flush waits until every queued entry is written or ctx is done
+func (q *asyncQueue) flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		for (len(q.items) > 0 || q.busy) && ctx.Err() == nil {
+			q.drained.Wait()
+		}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		// Wake the waiter so it sees ctx is done and exits
+		q.mu.Lock()
+		q.drained.Broadcast()
+		q.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// close stops accepting entries and waits until the worker has written the
+// queued ones or ctx is done
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Signal()
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush waits until the queues of all outputs are written or ctx is done
+func (mw *MultiWriter) flush(ctx context.Context) error {
+	for _, p := range mw.pairs {
+		if p.q == nil {
+			continue
+		}
+		if err := p.q.flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeQueues closes the queues of all outputs, writing what they hold
+func (mw *MultiWriter) closeQueues(ctx context.Context) error {
+	for _, p := range mw.pairs {
+		if p.q == nil {
+			continue
+		}
+		if err := p.q.close(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nopFormatter is the logger's formatter once the async hook writes its
+// entries, so they are not formatted twice
+type nopFormatter struct{}
+
+func (nopFormatter) Format(*logrus.Entry) ([]byte, error) { return nil, nil }
+
+// setAsync moves a logger that writes to l.Out onto an async MultiWriter with
+// the same formatter. Loggers with multi output or sinks already have one.
+func setAsync(l *logrus.Logger, cfg AsyncConfig) {
+	if multiWriterOf(l) != nil {
+		return
+	}
+	mw := NewMultiWriter(MultiWriterWithFormattersConfig{async: &cfg})
+	mw.addPair(l.Out, l.Formatter, nil)
+	l.AddHook(&CustomHook{mw: mw})
+	l.SetFormatter(nopFormatter{})
+	l.SetOutput(io.Discard) // Hook handles writing
+}
+
+// multiWriterOf returns the MultiWriter writing l's entries, if any
+func multiWriterOf(l *logrus.Logger) *MultiWriter {
+	if r := reloaderOf(l); r != nil {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		if r.state != nil {
+			return r.state.mw
+		}
+	}
+	for _, h := range l.Hooks[logrus.InfoLevel] {
+		if ch, ok := h.(*CustomHook); ok {
+			return ch.mw
+		}
+	}
+	return nil
+}
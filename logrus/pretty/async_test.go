@@ -0,0 +1,180 @@
+package pretty
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gatedWriter blocks every write until release is closed, and signals each
+// write it starts on started
+type gatedWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{started: make(chan struct{}, 16), release: make(chan struct{})}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	select {
+	case w.started <- struct{}{}:
+	default:
+	}
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// fillQueue enqueues "1" and waits until the worker is stuck writing it, then
+// fills the queue of size 2 with "2" and "3"
+func fillQueue(t *testing.T, cfg AsyncConfig) (*asyncQueue, *gatedWriter) {
+	t.Helper()
+	w := newGatedWriter()
+	cfg.QueueSize = 2
+	q := newAsyncQueue(w, cfg, nil)
+	q.enqueue([]byte("1\n"), logrus.InfoLevel)
+	<-w.started
+	q.enqueue([]byte("2\n"), logrus.InfoLevel)
+	q.enqueue([]byte("3\n"), logrus.InfoLevel)
+	return q, w
+}
+
+func drain(t *testing.T, q *asyncQueue, w *gatedWriter) string {
+	t.Helper()
+	close(w.release)
+	if err := q.close(context.Background()); err != nil {
+		t.Fatalf("close error: %v", err)
+	}
+	return strings.ReplaceAll(w.String(), "\n", "")
+}
+
+func TestAsyncQueue_Policies(t *testing.T) {
+	t.Run("drop newest", func(t *testing.T) {
+		q, w := fillQueue(t, AsyncConfig{Policy: QueueDropNewest})
+		q.enqueue([]byte("4\n"), logrus.ErrorLevel)
+		q.enqueue([]byte("5\n"), logrus.InfoLevel)
+		if out := drain(t, q, w); out != "123" || q.dropped.Load() != 2 {
+			t.Errorf("Expected 123 with 2 dropped, got %q with %d", out, q.dropped.Load())
+		}
+	})
+
+	t.Run("drop oldest", func(t *testing.T) {
+		q, w := fillQueue(t, AsyncConfig{Policy: QueueDropOldest})
+		q.enqueue([]byte("4\n"), logrus.InfoLevel)
+		q.enqueue([]byte("5\n"), logrus.InfoLevel)
+		if out := drain(t, q, w); out != "145" || q.dropped.Load() != 2 {
+			t.Errorf("Expected 145 with 2 dropped, got %q with %d", out, q.dropped.Load())
+		}
+	})
+
+	t.Run("drop below level", func(t *testing.T) {
+		q, w := fillQueue(t, AsyncConfig{Policy: QueueDropBelow, DropLevel: logrus.InfoLevel})
+		q.enqueue([]byte("4\n"), logrus.DebugLevel)
+
+		done := make(chan struct{})
+		go func() {
+			q.enqueue([]byte("5\n"), logrus.WarnLevel)
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Fatalf("Expected a warning to wait for room")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		close(w.release)
+		<-done
+		if err := q.close(context.Background()); err != nil {
+			t.Fatalf("close error: %v", err)
+		}
+		if out := strings.ReplaceAll(w.String(), "\n", ""); out != "1235" || q.dropped.Load() != 1 {
+			t.Errorf("Expected 1235 with 1 dropped, got %q with %d", out, q.dropped.Load())
+		}
+	})
+}
+
+func TestAsyncQueue_FlushDeadline(t *testing.T) {
+	q, w := fillQueue(t, AsyncConfig{})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.flush(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline while the writer is stuck, got %v", err)
+	}
+	if out := drain(t, q, w); out != "123" {
+		t.Errorf("Expected every entry after the writer recovers, got %q", out)
+	}
+}
+
+func TestWithAsync_SinkOrderAndDropped(t *testing.T) {
+	w := newGatedWriter()
+	close(w.release)
+	sink := NewSink(w, NewCustomFormatter(WithColors(false)))
+	logger := New(WithSink(sink), WithoutCaller(), WithAsync(AsyncConfig{QueueSize: 8}))
+
+	for i := range 50 {
+		logger.WithField("n", i).Info("entry")
+	}
+	if err := multiWriterOf(logger).flush(context.Background()); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+
+	out := w.String()
+	for i := 1; i < 50; i++ {
+		if strings.Index(out, "n="+strconv.Itoa(i-1)+"\n") > strings.Index(out, "n="+strconv.Itoa(i)+"\n") {
+			t.Fatalf("Expected entries in order, got %q", out)
+		}
+	}
+	if sink.Dropped() != 0 || Dropped(logger) != 0 {
+		t.Errorf("Expected nothing dropped with QueueBlock, got %d", sink.Dropped())
+	}
+}
+
+func TestWithAsync_FileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	logger := New(WithOutput(OutputFile), WithFile(path), WithAsync(AsyncConfig{}))
+	if _, ok := logger.Formatter.(nopFormatter); !ok || multiWriterOf(logger) == nil {
+		t.Fatalf("Expected file output to go through the async hook, got %T", logger.Formatter)
+	}
+
+	logger.Warn("[Disk] slow")
+	if err := multiWriterOf(logger).flush(context.Background()); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !strings.Contains(string(data), "slow") {
+		t.Errorf("Expected the entry in the file, got %q (%v)", data, err)
+	}
+}
+
+func TestWithAsync_FatalFlushes(t *testing.T) {
+	w := newGatedWriter()
+	close(w.release)
+	logger := New(WithSink(NewSink(w, nil)), WithAsync(AsyncConfig{}))
+	exited := false
+	logger.ExitFunc = func(int) { exited = true }
+
+	logger.Info("before")
+	logger.Fatal("fatal")
+	if out := w.String(); !exited || !strings.Contains(out, "before") || !strings.Contains(out, "fatal") {
+		t.Errorf("Expected queued entries written before exit, got %q", out)
+	}
+}
@@ -0,0 +1,126 @@
+package pretty
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Caller templates for CustomFormatter.CallerFormat. Placeholders:
+//
+//	{path}     file path, see CallerPathMode
+//	{file}     file name only, e.g. "login.go"
+//	{line}     line number
+//	{func}     function without package, e.g. "(*Server).Login"
+//	{pkg}      last element of the package path, e.g. "auth"
+//	{fullfunc} fully qualified function, e.g. "github.com/acme/app/auth.(*Server).Login"
+const (
+	CallerFormatDefault  = "└─ at ({path}:{line})" // The original second-line format
+	CallerFormatInline   = "({path}:{line})"       // Default for CallerInline
+	CallerFormatFunction = "└─ at {pkg}.{func} ({path}:{line})"
+	CallerFormatShort    = "{pkg}.{func} ({file}:{line})"
+)
+
+// CallerPlacement defines where the caller is shown
+type CallerPlacement int
+
+const (
+	CallerBelow  CallerPlacement = iota // On its own line, indented to the message column
+	CallerInline                        // At the end of the log line
+)
+
+// CallerPathMode defines how {path} is written in caller templates
+type CallerPathMode int
+
+const (
+	CallerPathWorkDir CallerPathMode = iota // Relative to the working directory or absolute, per UseRelativePath
+	CallerPathModule                        // Relative to the directory of the nearest go.mod
+	CallerPathBase                          // File name only
+)
+
+// WithCallerFormat sets the caller template and where it is placed.
+// An empty format uses CallerFormatDefault or CallerFormatInline.
+func WithCallerFormat(format string, placement CallerPlacement) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.CallerFormat = format
+		f.CallerPlacement = placement
+	}
+}
+
+// WithCallerPath sets how the {path} placeholder is resolved
+func WithCallerPath(mode CallerPathMode) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.CallerPath = mode
+	}
+}
+
+// expandCaller fills a caller template for frame
+func (f *CustomFormatter) expandCaller(format string, frame *runtime.Frame) string {
+	pkg, fn := splitFunction(frame.Function)
+	return strings.NewReplacer(
+		"{path}", f.callerFilePath(frame.File),
+		"{file}", filepath.Base(frame.File),
+		"{line}", strconv.Itoa(frame.Line),
+		"{func}", fn,
+		"{pkg}", pkg,
+		"{fullfunc}", frame.Function,
+	).Replace(format)
+}
+
+func (f *CustomFormatter) callerFilePath(file string) string {
+	switch f.CallerPath {
+	case CallerPathModule:
+		if root, ok := moduleRoot(filepath.Dir(file)); ok {
+			if rel, err := filepath.Rel(root, file); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+		return callerPath(file, f.UseRelativePath)
+	case CallerPathBase:
+		return filepath.Base(file)
+	default:
+		return callerPath(file, f.UseRelativePath)
+	}
+}
+
+// splitFunction splits "github.com/acme/app/auth.(*Server).Login" into the short
+// package "auth" and the function "(*Server).Login"
+func splitFunction(full string) (pkg, fn string) {
+	slash := strings.LastIndex(full, "/")
+	dot := strings.Index(full[slash+1:], ".")
+	if dot < 0 {
+		return "", full
+	}
+	dot += slash + 1
+	return full[slash+1 : dot], full[dot+1:]
+}
+
+// moduleRoots caches the go.mod directory found for each source directory
+var moduleRoots sync.Map // string -> string ("" when there is none)
+
+// moduleRoot returns the nearest directory at or above dir that holds a go.mod.
+// Paths built with -trimpath are not absolute and never have one.
+func moduleRoot(dir string) (string, bool) {
+	if !filepath.IsAbs(dir) {
+		return "", false
+	}
+	if root, ok := moduleRoots.Load(dir); ok {
+		return root.(string), root.(string) != ""
+	}
+
+	root := ""
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			root = d
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	moduleRoots.Store(dir, root)
+	return root, root != ""
+}
@@ -0,0 +1,109 @@
+package pretty
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func callerEntry(frame *runtime.Frame) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "[Auth] failed"
+	entry.Level = logrus.ErrorLevel
+	entry.Caller = frame
+	return entry
+}
+
+func TestSplitFunction(t *testing.T) {
+	tests := []struct{ in, pkg, fn string }{
+		{"github.com/acme/app/auth.(*Server).Login", "auth", "(*Server).Login"},
+		{"github.com/acme/app/auth.Login.func1", "auth", "Login.func1"},
+		{"main.main", "main", "main"},
+		{"gopkg.in/yaml.v3.Unmarshal", "yaml", "v3.Unmarshal"},
+		{"nodot", "", "nodot"},
+	}
+	for _, tt := range tests {
+		pkg, fn := splitFunction(tt.in)
+		if pkg != tt.pkg || fn != tt.fn {
+			t.Errorf("splitFunction(%q) = %q, %q; want %q, %q", tt.in, pkg, fn, tt.pkg, tt.fn)
+		}
+	}
+}
+
+func TestFormatter_CallerFormat(t *testing.T) {
+	frame := &runtime.Frame{File: "/src/app/auth/login.go", Line: 42, Function: "github.com/acme/app/auth.(*Server).Login"}
+
+	tests := []struct {
+		name   string
+		format string
+		path   CallerPathMode
+		want   string
+	}{
+		{"Short", CallerFormatShort, CallerPathWorkDir, "auth.(*Server).Login (login.go:42)"},
+		{"Function", CallerFormatFunction, CallerPathBase, "└─ at auth.(*Server).Login (login.go:42)"},
+		{"Full", "{fullfunc}:{line}", CallerPathWorkDir, "github.com/acme/app/auth.(*Server).Login:42"},
+		{"Absolute", "{path}", CallerPathWorkDir, "/src/app/auth/login.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewCustomFormatter(WithColors(false), WithRelativePath(false),
+				WithCallerFormat(tt.format, CallerBelow), WithCallerPath(tt.path))
+			if got := f.formatCallerInfo(callerEntry(frame)); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatter_CallerModulePath(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "internal", "auth", "login.go")
+
+	f := NewCustomFormatter(WithColors(false), WithCallerFormat("{path}:{line}", CallerBelow), WithCallerPath(CallerPathModule))
+	got := f.formatCallerInfo(callerEntry(&runtime.Frame{File: file, Line: 7}))
+	if got != "internal/auth/login.go:7" {
+		t.Errorf("Expected module-relative path, got %q", got)
+	}
+
+	// Without a go.mod the working-directory rule applies
+	if got := f.callerFilePath("rel/path.go"); got != callerPath("rel/path.go", f.UseRelativePath) {
+		t.Errorf("Expected fallback for trimmed paths, got %q", got)
+	}
+}
+
+func TestFormatter_CallerInline(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithNamespaceColumn(false, 0),
+		WithCallerFormat(CallerFormatShort, CallerInline))
+	frame := &runtime.Frame{File: "/src/app/auth/login.go", Line: 42, Function: "github.com/acme/app/auth.Login"}
+
+	b, err := f.Format(callerEntry(frame))
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+	if got := string(b); got != "ERROR  [Auth]          failed  auth.Login (login.go:42)\n" {
+		t.Errorf("Expected caller on the same line, got %q", got)
+	}
+
+	// The default inline template has no second-line marker
+	f.CallerFormat = ""
+	f.CallerPath = CallerPathBase
+	b, _ = f.Format(callerEntry(frame))
+	if got := string(b); strings.Contains(got, "└─") || !strings.HasSuffix(got, "failed  (login.go:42)\n") {
+		t.Errorf("Expected default inline caller, got %q", got)
+	}
+}
+
+func TestFormatter_CallerColorWithoutParentheses(t *testing.T) {
+	f := NewCustomFormatter(WithCallerFormat("{file}:{line}", CallerBelow))
+	got := f.formatCallerInfo(callerEntry(&runtime.Frame{File: "/a/b.go", Line: 1}))
+	if got != ColorVeryDimGray+"b.go:1"+ColorReset {
+		t.Errorf("Expected the whole caller colored, got %q", got)
+	}
+}
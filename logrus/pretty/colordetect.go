@@ -0,0 +1,69 @@
+package pretty
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// DetectColors reports whether w can show colors and how many, following the
+// common conventions in this order:
+//
+//   - NO_COLOR set to anything non-empty disables colors
+//   - FORCE_COLOR enables them ("0" or "false" disables; "2" means 256 colors, "3" truecolor)
+//   - CLICOLOR_FORCE other than "0" enables them
+//   - CLICOLOR=0 or TERM=dumb disables them
+//   - otherwise colors are used only when w is a terminal
+//
+// The depth comes from COLORTERM ("truecolor", "24bit") and TERM ("*-256color").
+func DetectColors(w io.Writer) (enabled bool, depth ColorDepth) {
+	depth = detectColorDepth()
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false, depth
+	}
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		switch strings.ToLower(strings.TrimSpace(force)) {
+		case "0", "false":
+			return false, depth
+		case "2":
+			return true, max(depth, ColorDepth256)
+		case "3":
+			return true, ColorDepthTrue
+		default:
+			return true, depth
+		}
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true, depth
+	}
+	if os.Getenv("CLICOLOR") == "0" || os.Getenv("TERM") == "dumb" {
+		return false, depth
+	}
+	return isTerminal(w), depth
+}
+
+// detectColorDepth reads the color depth advertised by the terminal
+func detectColorDepth() ColorDepth {
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorDepthTrue
+	}
+	t := strings.ToLower(os.Getenv("TERM"))
+	switch {
+	case strings.Contains(t, "truecolor"), strings.Contains(t, "direct"):
+		return ColorDepthTrue
+	case strings.Contains(t, "256color"):
+		return ColorDepth256
+	default:
+		return ColorDepth16
+	}
+}
+
+// isTerminal reports whether w is a file attached to a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(int(f.Fd()))
+}
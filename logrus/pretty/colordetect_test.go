@@ -0,0 +1,113 @@
+package pretty
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// clearColorEnv unsets every variable DetectColors reads
+func clearColorEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR", "CLICOLOR_FORCE", "TERM", "COLORTERM"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func TestDetectColors(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantEnabled bool
+		wantDepth   ColorDepth
+	}{
+		{"NotATerminal", nil, false, ColorDepth16},
+		{"ForceColor", map[string]string{"FORCE_COLOR": "1"}, true, ColorDepth16},
+		{"ForceColorEmpty", map[string]string{"FORCE_COLOR": ""}, true, ColorDepth16},
+		{"ForceColor256", map[string]string{"FORCE_COLOR": "2"}, true, ColorDepth256},
+		{"ForceColorTrue", map[string]string{"FORCE_COLOR": "3"}, true, ColorDepthTrue},
+		{"ForceColorOff", map[string]string{"FORCE_COLOR": "0"}, false, ColorDepth16},
+		{"NoColorWins", map[string]string{"NO_COLOR": "1", "FORCE_COLOR": "1"}, false, ColorDepth16},
+		{"CliColorForce", map[string]string{"CLICOLOR_FORCE": "1"}, true, ColorDepth16},
+		{"DepthFromTerm", map[string]string{"FORCE_COLOR": "1", "TERM": "xterm-256color"}, true, ColorDepth256},
+		{"DepthFromColorTerm", map[string]string{"FORCE_COLOR": "1", "TERM": "xterm-256color", "COLORTERM": "truecolor"}, true, ColorDepthTrue},
+		{"DumbTerm", map[string]string{"TERM": "dumb"}, false, ColorDepth16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearColorEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			enabled, depth := DetectColors(&bytes.Buffer{})
+			if enabled != tt.wantEnabled || depth != tt.wantDepth {
+				t.Errorf("DetectColors() = %v, %d; want %v, %d", enabled, depth, tt.wantEnabled, tt.wantDepth)
+			}
+		})
+	}
+}
+
+func TestIsTerminal_File(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("Expected a regular file not to be a terminal")
+	}
+}
+
+func TestNew_NoColorsWhenPiped(t *testing.T) {
+	clearColorEnv(t)
+
+	logger := New()
+	f, ok := logger.Formatter.(*CustomFormatter)
+	if !ok {
+		t.Fatalf("Expected CustomFormatter, got %T", logger.Formatter)
+	}
+	if f.UseColors {
+		t.Error("Expected no colors when stdout is not a terminal")
+	}
+
+	t.Setenv("FORCE_COLOR", "3")
+	f = New().Formatter.(*CustomFormatter)
+	if !f.UseColors || f.ColorDepth != ColorDepthTrue {
+		t.Errorf("Expected forced truecolor, got colors=%v depth=%d", f.UseColors, f.ColorDepth)
+	}
+}
+
+func TestMultiWriter_AddWriterDetectsColors(t *testing.T) {
+	clearColorEnv(t)
+
+	var buf bytes.Buffer
+	mw := NewMultiWriter(MultiWriterWithFormattersConfig{format: FormatPlain})
+	mw.AddWriter(&buf, true, false)
+
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "[DB] ready"
+	entry.Level = logrus.InfoLevel
+	if err := mw.WriteEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\033[") {
+		t.Errorf("Expected no colors for a non-terminal writer, got %q", buf.String())
+	}
+
+	t.Setenv("FORCE_COLOR", "1")
+	buf.Reset()
+	mw = NewMultiWriter(MultiWriterWithFormattersConfig{format: FormatPlain})
+	mw.AddWriter(&buf, true, false)
+	if err := mw.WriteEntry(entry); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), ColorGreen) {
+		t.Errorf("Expected colors with FORCE_COLOR, got %q", buf.String())
+	}
+}
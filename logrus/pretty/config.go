@@ -1,8 +1,10 @@
 package pretty
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -11,9 +13,10 @@ import (
 type FormatType int
 
 const (
-	FormatRaw   FormatType = iota // 0
-	FormatPlain                   // 1
-	FormatJSON                    // 2
+	FormatRaw        FormatType = iota // 0
+	FormatPlain                        // 1
+	FormatJSON                         // 2
+	FormatJSONTagged                   // 3: JSON with the bracket tag as its own field
 )
 
 type OutputType int
@@ -24,6 +27,30 @@ const (
 	OutputMulti
 )
 
+func (f FormatType) String() string {
+	switch f {
+	case FormatPlain:
+		return "plain"
+	case FormatJSON:
+		return "json"
+	case FormatJSONTagged:
+		return "json-tagged"
+	default:
+		return "raw"
+	}
+}
+
+func (o OutputType) String() string {
+	switch o {
+	case OutputFile:
+		return "file"
+	case OutputMulti:
+		return "multi"
+	default:
+		return "console"
+	}
+}
+
 type FormatterOptions struct {
 	Level      *logrus.Level
 	Output     *OutputType
@@ -41,53 +68,103 @@ type Config struct {
 
 	// Environment Mapping
 
-	EnvLevel  string
-	EnvOutput string
-	EnvFormat string
+	EnvLevel     string
+	EnvOutput    string
+	EnvFormat    string
+	EnvTagLevels string
+	EnvTheme     string
+
+	EnvFile       string
+	EnvMaxSize    string
+	EnvMaxBackups string
+	EnvMaxAge     string
+	EnvCompress   string
+
+	// Theme colors the plain formatter, see WithThemeName. nil uses ThemeDark.
+	Theme *Theme
+
+	// TagLevels sets the most verbose level per bracket tag, see WithTagLevels
+	TagLevels map[string]logrus.Level
+
+	// Redactor masks secrets before any output, see WithRedaction
+	Redactor *Redactor
+
+	// Sinks replace the output type when set, see WithSink
+	Sinks []*Sink
+
+	// Async writes entries in the background, see WithAsync
+	Async *AsyncConfig
 
 	// Persistence
 
 	Filename  string
-	Namespace string // "LoggerName" is often called Namespace or Scope
+	Namespace string         // "LoggerName" is often called Namespace or Scope
+	Rotation  *LogFileConfig // A zero config uses DefaultLogFileConfig(), see WithFileRotation
+
+	// ConfigFile is the path given to WithConfigFile, if any
+	ConfigFile string
+
+	// fileRotation applies the rotation section of a config file, see logFileConfig
+	fileRotation func(*LogFileConfig)
+	// resolvers finish settings that depend on other options, e.g. the colors
+	// of a config file's formatter on the output. apply runs them once all
+	// options have run, see resolve.
+	resolvers []func(*Config)
+
+	err error // First error raised while applying options, reported by setup
 }
 
 func (c Config) setLevel(l *logrus.Logger) {
 	// If user called WithLevel(), c.Level is not nil
 	if c.Level != nil {
-		l.SetLevel(*c.Level)
+		SetLevel(l, *c.Level)
 		return
 	}
 
 	// Fallback to Environment
 	if env := os.Getenv(c.EnvLevel); env != "" {
 		if p, err := logrus.ParseLevel(env); err == nil {
-			l.SetLevel(p)
+			SetLevel(l, p)
 			return
 		}
 	}
-	l.SetLevel(logrus.InfoLevel)
+	SetLevel(l, logrus.InfoLevel)
 }
 
 func (c Config) setOutput(l *logrus.Logger) {
-	out := OutputConsole // Default
-	if c.Output != nil {
-		out = *c.Output
-	} else if env := os.Getenv(c.EnvOutput); env != "" {
-		out = parseOutputType(env)
+	if len(c.Sinks) > 0 {
+		mw := NewMultiWriter(MultiWriterWithFormattersConfig{
+			format:       c.getFormat(),
+			showCaller:   c.ShowCaller,
+			customFormat: c.CustomFormat,
+			theme:        c.Theme,
+			async:        c.Async,
+		})
+		for _, s := range c.Sinks {
+			mw.AddSink(s)
+		}
+		l.AddHook(&CustomHook{mw: mw})
+		l.SetOutput(io.Discard) // Hook handles writing
+		return
 	}
 
-	switch out {
+	output := c.resolveOutput()
+	filename := c.getFilename()
+
+	switch output {
 	case OutputFile:
-		l.SetOutput(NewLumberjackLogger(c.Filename, DefaultLogFileConfig()))
+		l.SetOutput(c.openLogFile(filename))
 
 	case OutputMulti:
-		logFile := NewLumberjackLogger(c.Filename, DefaultLogFileConfig())
+		logFile := c.openLogFile(filename)
 
 		// Create the multi-writer config using the resolved format
 		mwConfig := MultiWriterWithFormattersConfig{
 			format:       c.getFormat(),
 			showCaller:   c.ShowCaller,
 			customFormat: c.CustomFormat,
+			theme:        c.Theme,
+			async:        c.Async,
 		}
 
 		mw := NewMultiWriter(mwConfig)
@@ -102,6 +179,92 @@ func (c Config) setOutput(l *logrus.Logger) {
 	}
 }
 
+// getOutput resolves the output type from Struct -> Env -> Default
+func (c Config) getOutput() OutputType {
+	if c.Output != nil {
+		return *c.Output
+	}
+	if env := os.Getenv(c.EnvOutput); env != "" {
+		return parseOutputType(env)
+	}
+	return OutputConsole
+}
+
+// openLogFile opens filename with the resolved rotation settings. A config
+// NewLogFile rejects is reported and the file rotates by size only.
+func (c Config) openLogFile(filename string) io.WriteCloser {
+	config := c.logFileConfig()
+	f, err := NewLogFile(filename, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "log config err: %v; rotating by size only\n", err)
+		return NewLumberjackLogger(filename, config)
+	}
+	return f
+}
+
+// resolveOutput is getOutput, falling back to console for file and multi
+// output without a file name
+func (c Config) resolveOutput() OutputType {
+	output := c.getOutput()
+	if (output == OutputFile || output == OutputMulti) && c.getFilename() == "" {
+		fmt.Fprintf(os.Stderr, "log config err: output %s needs a file name, see WithFile or %s; logging to stdout\n", output, c.EnvFile)
+		return OutputConsole
+	}
+	return output
+}
+
+// getFilename resolves the log file path from Struct -> Env
+func (c Config) getFilename() string {
+	if c.Filename != "" {
+		return c.Filename
+	}
+	return os.Getenv(c.EnvFile)
+}
+
+// logFileConfig resolves the rotation settings from Struct -> Env -> Default,
+// then applies the rotation section of a config file. A zero struct and
+// invalid env values keep the default.
+func (c Config) logFileConfig() LogFileConfig {
+	config := c.baseLogFileConfig()
+	if c.fileRotation != nil {
+		c.fileRotation(&config)
+	}
+	return config
+}
+
+func (c Config) baseLogFileConfig() LogFileConfig {
+	if c.Rotation != nil {
+		return c.Rotation.withDefaults()
+	}
+
+	config := DefaultLogFileConfig()
+	envInt(c.EnvMaxSize, &config.MaxSize)
+	envInt(c.EnvMaxBackups, &config.MaxBackups)
+	envInt(c.EnvMaxAge, &config.MaxAge)
+	if env := os.Getenv(c.EnvCompress); c.EnvCompress != "" && env != "" {
+		if b, err := strconv.ParseBool(strings.TrimSpace(env)); err == nil {
+			config.Compress = b
+		} else {
+			fmt.Fprintf(os.Stderr, "log config err: %s: invalid value %q\n", c.EnvCompress, env)
+		}
+	}
+	return config
+}
+
+// envInt sets *dst from the env var name when it holds a non-negative integer
+func envInt(name string, dst *int) {
+	env := os.Getenv(name)
+	if name == "" || env == "" {
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(env))
+	if err != nil || n < 0 {
+		fmt.Fprintf(os.Stderr, "log config err: %s: invalid value %q\n", name, env)
+		return
+	}
+	*dst = n
+}
+
 func parseOutputType(env string) OutputType {
 	switch strings.ToLower(strings.TrimSpace(env)) {
 	case "file":
@@ -116,8 +279,29 @@ func parseOutputType(env string) OutputType {
 }
 
 func (c Config) setFormatter(l *logrus.Logger) {
+	// Colors are only used on a console that supports them, see DetectColors
+	output := c.getOutput()
+	useColors, depth := false, ColorDepth(0)
+	if output == OutputConsole && len(c.Sinks) == 0 {
+		useColors, depth = DetectColors(os.Stdout)
+	}
+
 	if c.CustomFormat != nil {
-		l.SetFormatter(c.CustomFormat)
+		f := c.CustomFormat
+		if output != OutputConsole {
+			f = f.forFile()
+		}
+		if (f.Theme == nil && c.Theme != nil) || (f.ColorDepth == 0 && depth != 0) {
+			themed := *f
+			if themed.Theme == nil {
+				themed.Theme = c.Theme
+			}
+			if themed.ColorDepth == 0 {
+				themed.ColorDepth = depth
+			}
+			f = &themed
+		}
+		l.SetFormatter(f)
 		l.SetReportCaller(c.CustomFormat.ShowCaller)
 		return
 	}
@@ -129,11 +313,15 @@ func (c Config) setFormatter(l *logrus.Logger) {
 	case FormatJSON:
 		l.SetFormatter(&logrus.JSONFormatter{})
 
+	case FormatJSONTagged:
+		f := NewJSONFormatter()
+		f.ShowCaller = c.ShowCaller
+		l.SetFormatter(f)
+
 	case FormatPlain:
-		// If using Multi, the Hook handles formatting; don't set a global formatter
-		isMulti := c.Output != nil && *c.Output == OutputMulti
+		// If using Multi or sinks, the Hook handles formatting; don't set a global formatter
+		isMulti := output == OutputMulti || len(c.Sinks) > 0
 		if !isMulti {
-			useColors := c.Output != nil && *c.Output == OutputConsole
 			l.SetFormatter(&CustomFormatter{
 				UseColors:       useColors,
 				ShowCaller:      c.ShowCaller,
@@ -142,11 +330,18 @@ func (c Config) setFormatter(l *logrus.Logger) {
 				UseRelativePath: true,
 				BracketPadding:  15,
 				ColorBrackets:   true,
+				ShowNamespace:   true,
+				Theme:           c.Theme,
+				ColorDepth:      depth,
+				ErrorTree:       true,
+				ErrorStacks:     true,
+				StackLevel:      logrus.ErrorLevel,
+				Multiline:       MultilineIndent,
 			})
 		}
 
 	default: // FormatRaw
-		l.SetFormatter(&logrus.TextFormatter{})
+		l.SetFormatter(&logrus.TextFormatter{ForceColors: useColors, DisableColors: !useColors})
 	}
 }
 
@@ -167,23 +362,131 @@ func parseFormatType(env string) FormatType {
 		return FormatJSON
 	case "console":
 		return FormatPlain
+	case "json-tagged":
+		return FormatJSONTagged
 	default:
 		return FormatRaw
 	}
 }
 
-func setup(l *logrus.Logger, cfg Config) {
-	cfg.setLevel(l)
-	cfg.setOutput(l)
-	cfg.setFormatter(l)
+func setup(l *logrus.Logger, cfg Config) Config {
+	if cfg.err != nil {
+		fmt.Fprintf(os.Stderr, "log config err: %v\n", cfg.err)
+	}
+	setNamespace(l, cfg.Namespace)
+	if cfg.Redactor != nil {
+		setRedactor(l, cfg.Redactor)
+	}
+	cfg = cfg.apply(l)
 
 	logInitComplete(l, cfg)
+	return cfg
+}
+
+// apply sets level, output and formatter on l without logging anything. It
+// returns c with the output, format and file name it resolved, so they are
+// reported as used even if the env vars change later.
+func (c Config) apply(l *logrus.Logger) Config {
+	c.Theme = c.getTheme()
+	output := c.resolveOutput() // So the formatter matches the output used
+	c.Output = &output
+	c.resolve()
+	format := c.getFormat()
+	c.Format = &format
+	if output == OutputConsole {
+		c.Filename = ""
+	} else {
+		c.Filename = c.getFilename()
+	}
+	c.setLevel(l)
+	filter := c.getTagFilter(GetLevel(l))
+	if filter == nil && c.sinkLevel() != logrus.PanicLevel {
+		// Holds the outputs without a level of their own to the logger level
+		filter = newTagFilter(nil, GetLevel(l))
+	}
+	c.setOutput(l)
+	c.setFormatter(l)
+	if c.Async != nil {
+		setAsync(l, *c.Async)
+	}
+	if filter != nil {
+		setTagFilter(l, filter, max(filter.mostVerbose(), c.sinkLevel()))
+	}
+	return c
+}
+
+// resolve runs the resolvers left by options, once
+func (c *Config) resolve() {
+	for _, r := range c.resolvers {
+		r(c)
+	}
+	c.resolvers = nil
+}
+
+// getTheme resolves the theme from Struct -> Env -> Default (nil)
+func (c Config) getTheme() *Theme {
+	if c.Theme != nil {
+		return c.Theme
+	}
+	if c.EnvTheme == "" {
+		return nil
+	}
+	env := os.Getenv(c.EnvTheme)
+	if env == "" {
+		return nil
+	}
+	t, ok := LookupTheme(env)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "log config err: %s: unknown theme %q (want %s)\n", c.EnvTheme, env, strings.Join(ThemeNames(), ", "))
+		return nil
+	}
+	return t
+}
+
+// getTagFilter resolves tag levels from Struct -> Env. Untagged messages fall back
+// to the given level unless the levels contain a "*" entry.
+func (c Config) getTagFilter(fallback logrus.Level) *tagFilter {
+	levels := c.TagLevels
+	if levels == nil && c.EnvTagLevels != "" {
+		if env := os.Getenv(c.EnvTagLevels); env != "" {
+			parsed, err := ParseTagLevels(env)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "log config err: %s: %v\n", c.EnvTagLevels, err)
+				return nil
+			}
+			levels = parsed
+		}
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+	return newTagFilter(levels, fallback)
+}
+
+// setTagFilter lowers the logger's gate to floor so every tag and sink can
+// pass, then lets the filter drop entries in the formatter or the multi-output
+// hook. The level of untagged entries becomes the filter's fallback, which is
+// the "*" entry if any.
+func setTagFilter(l *logrus.Logger, filter *tagFilter, floor logrus.Level) {
+	if h := hookOf(l); h != nil {
+		h.floor.Store(uint32(floor))
+		h.tags.Store(filter)
+		SetLevel(l, filter.fallback)
+	} else {
+		l.SetLevel(max(filter.fallback, floor))
+	}
+	l.SetFormatter(&filteredFormatter{Formatter: l.Formatter, filter: filter})
+	for _, h := range l.Hooks[logrus.InfoLevel] {
+		if ch, ok := h.(*CustomHook); ok {
+			ch.mw.filter = filter
+		}
+	}
 }
 
 func logInitComplete(logger *logrus.Logger, cfg Config) {
 	logger.Debugf("[Logger] %s initialized - Level: %s, Namespace: %s",
 		cfg.Namespace,
-		logger.GetLevel(),
+		GetLevel(logger),
 		cfg.Namespace,
 	)
 }
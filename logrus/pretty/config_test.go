@@ -71,6 +71,82 @@ func TestConfig_setOutput_File(t *testing.T) {
 	}
 }
 
+func TestConfig_setOutput_FileFromEnv(t *testing.T) {
+	t.Setenv("LOG_FILE", "env.log")
+	t.Setenv("LOG_MAX_SIZE", "50")
+	t.Setenv("LOG_COMPRESS", "false")
+	t.Setenv("LOG_MAX_AGE", "-1") // Invalid, keeps the default
+	logger := logrus.New()
+	cfg := newConfig(WithOutput(OutputFile))
+
+	cfg.setOutput(logger)
+
+	lj, ok := logger.Out.(*lumberjack.Logger)
+	if !ok {
+		t.Fatalf("Expected lumberjack logger for file output, got %T", logger.Out)
+	}
+	if lj.Filename != "env.log" || lj.MaxSize != 50 || lj.Compress || lj.MaxAge != 31 || lj.MaxBackups != 5 {
+		t.Errorf("Expected LOG_FILE and rotation env vars over the defaults, got %+v", lj)
+	}
+}
+
+func TestConfig_setOutput_FileRotationOption(t *testing.T) {
+	t.Setenv("LOG_MAX_SIZE", "50")
+	logger := logrus.New()
+	rot := NewLogFileConfig(20, 2, 7, false)
+	cfg := newConfig(WithOutput(OutputFile), WithFile("test.log"), WithFileRotation(rot))
+
+	cfg.setOutput(logger)
+
+	lj := logger.Out.(*lumberjack.Logger)
+	if lj.MaxSize != 20 || lj.MaxBackups != 2 || lj.MaxAge != 7 || lj.Compress {
+		t.Errorf("Expected WithFileRotation over env vars, got %+v", lj)
+	}
+}
+
+func TestConfig_setOutput_FileWithoutName(t *testing.T) {
+	t.Setenv("LOG_FILE", "")
+	for _, output := range []OutputType{OutputFile, OutputMulti} {
+		logger := logrus.New()
+		cfg := newConfig(WithOutput(output))
+
+		cfg.setOutput(logger)
+
+		if logger.Out != os.Stdout || len(logger.Hooks) != 0 {
+			t.Errorf("Expected %s output without a file name to fall back to stdout, got %T", output, logger.Out)
+		}
+	}
+}
+
+func TestConfig_setOutput_MultiWithoutName(t *testing.T) {
+	t.Setenv("LOG_FILE", "")
+	logger := logrus.New()
+	cfg := newConfig(WithOutput(OutputMulti), WithFormat(FormatPlain))
+
+	cfg.apply(logger)
+
+	if _, ok := logger.Formatter.(*CustomFormatter); !ok || logger.Out != os.Stdout {
+		t.Errorf("Expected the plain formatter on stdout, got %T on %T", logger.Formatter, logger.Out)
+	}
+}
+
+func TestConfig_setOutput_FileRotationDefaults(t *testing.T) {
+	logger := logrus.New()
+	cfg := newConfig(WithOutput(OutputFile), WithFile("test.log"), WithFileRotation(LogFileConfig{}))
+
+	cfg.setOutput(logger)
+
+	lj := logger.Out.(*lumberjack.Logger)
+	if lj.MaxSize != 10 || lj.MaxBackups != 5 || lj.MaxAge != 31 || !lj.Compress {
+		t.Errorf("Expected a zero config to use the defaults, got %+v", lj)
+	}
+
+	rot := newConfig(WithFileRotation(LogFileConfig{Interval: RotateDaily, MaxSize: 50})).logFileConfig()
+	if rot.MaxSize != 50 || rot.MaxBackups != 0 || rot.MaxAge != 0 || rot.Compress || rot.Interval != RotateDaily {
+		t.Errorf("Expected the config as given, with 0 keeping all files and no compression, got %+v", rot)
+	}
+}
+
 func TestConfig_setOutput_Multi(t *testing.T) {
 	logger := logrus.New()
 	output := OutputMulti
@@ -0,0 +1,821 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/rivo/uniseg"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig is the on-disk form of Config, loaded by LoadConfig and WithConfigFile.
+// The file may be YAML, JSON or TOML (picked by extension) and every key is optional:
+//
+//	level: debug
+//	output: multi            # console, file or multi
+//	format: plain            # raw, plain, json or json-tagged
+//	filename: logs/service.log
+//	namespace: App
+//	caller: true
+//	tag_levels:              # see WithTagLevels
+//	  DB: debug
+//	  HTTP: warn
+//	theme: light             # dark, light, solarized, high-contrast, monochrome or registered
+//	theme_colors:            # override single colors of the theme
+//	  info: "#00af5f"        # Name, 256-color index or "#rrggbb"
+//	  field_key: 244
+//	rotation:
+//	  max_size: 10           # megabytes
+//	  max_backups: 5
+//	  max_age: 31            # days
+//	  compress: true
+//	  interval: daily        # none, hourly or daily
+//	  utc: false
+//	  pattern: "service-%Y-%m-%d.log"
+//	  max_total_size: 500    # megabytes across all files
+//	formatter:               # only valid with format: plain
+//	  colors: true
+//	  timestamp: false
+//	  caller: true
+//	  caller_level: warn
+//	  caller_format: "{pkg}.{func} ({file}:{line})"
+//	  caller_placement: inline # below or inline
+//	  caller_path: module    # workdir, module or base
+//	  caller_link: vscode    # file, vscode, idea or a URL template
+//	  caller_link_commit: v1.2.0
+//	  caller_link_always: true # links on terminals DetectHyperlinks doesn't know
+//	  relative_path: true
+//	  bracket_padding: 15
+//	  color_brackets: true
+//	  tag_style: right       # default, center or right
+//	  padding_char: "•"
+//	  tag_path: joined       # off, joined or columns
+//	  tag_separator: "›"
+//	  tag_color_mode: 256    # fixed, 256 or truecolor
+//	  tag_colors:
+//	    DB: cyan             # Name, 256-color index or "#rrggbb"
+//	  namespace: true
+//	  namespace_padding: 10
+//	  error_tree: true       # wrapped errors as a tree below the line
+//	  error_stacks: true     # stacks carried by errors
+//	  capture_stack: true    # goroutine stack for entries at stack_level and above
+//	  stack_level: error
+//	  multiline: indent      # indent, escape or verbatim
+//	  multiline_gutter: "│ "
+//	  line_width: -1         # cells, -1 for the terminal width; console only
+//	  overflow: wrap         # wrap or truncate
+//	  nested_fields: flatten # flatten or json
+//	  pinned_fields: [request_id, user, status]
+//	  hidden_fields: [trace_id] # console only
+//	  field_order: sorted    # sorted or insertion
+type FileConfig struct {
+	Level       *string              `json:"level" yaml:"level" toml:"level"`
+	Output      *string              `json:"output" yaml:"output" toml:"output"`
+	Format      *string              `json:"format" yaml:"format" toml:"format"`
+	Filename    *string              `json:"filename" yaml:"filename" toml:"filename"`
+	Namespace   *string              `json:"namespace" yaml:"namespace" toml:"namespace"`
+	Caller      *bool                `json:"caller" yaml:"caller" toml:"caller"`
+	TagLevels   map[string]string    `json:"tag_levels" yaml:"tag_levels" toml:"tag_levels"`
+	Theme       *string              `json:"theme" yaml:"theme" toml:"theme"`
+	ThemeColors map[string]string    `json:"theme_colors" yaml:"theme_colors" toml:"theme_colors"`
+	Rotation    *RotationFileConfig  `json:"rotation" yaml:"rotation" toml:"rotation"`
+	Formatter   *FormatterFileConfig `json:"formatter" yaml:"formatter" toml:"formatter"`
+
+	// lines maps dotted keys to their line in the source file, when the decoder knows it
+	lines map[string]int
+}
+
+// RotationFileConfig is the "rotation" section of a FileConfig.
+// Unset keys keep the values from DefaultLogFileConfig().
+type RotationFileConfig struct {
+	MaxSize    *int  `json:"max_size" yaml:"max_size" toml:"max_size"`
+	MaxBackups *int  `json:"max_backups" yaml:"max_backups" toml:"max_backups"`
+	MaxAge     *int  `json:"max_age" yaml:"max_age" toml:"max_age"`
+	Compress   *bool `json:"compress" yaml:"compress" toml:"compress"`
+
+	Interval     *string `json:"interval" yaml:"interval" toml:"interval"`
+	UTC          *bool   `json:"utc" yaml:"utc" toml:"utc"`
+	Pattern      *string `json:"pattern" yaml:"pattern" toml:"pattern"`
+	MaxTotalSize *int    `json:"max_total_size" yaml:"max_total_size" toml:"max_total_size"`
+}
+
+// FormatterFileConfig is the "formatter" section of a FileConfig.
+// Unset keys keep the values of the plain formatter of New, see
+// newLoggerFormatter, except colors which default to on only for console output.
+type FormatterFileConfig struct {
+	Colors         *bool   `json:"colors" yaml:"colors" toml:"colors"`
+	Timestamp      *bool   `json:"timestamp" yaml:"timestamp" toml:"timestamp"`
+	Caller         *bool   `json:"caller" yaml:"caller" toml:"caller"`
+	CallerLevel    *string `json:"caller_level" yaml:"caller_level" toml:"caller_level"`
+	CallerFormat   *string `json:"caller_format" yaml:"caller_format" toml:"caller_format"`
+	CallerPlace    *string `json:"caller_placement" yaml:"caller_placement" toml:"caller_placement"`
+	CallerPath     *string `json:"caller_path" yaml:"caller_path" toml:"caller_path"`
+	CallerLink     *string `json:"caller_link" yaml:"caller_link" toml:"caller_link"`
+	CallerCommit   *string `json:"caller_link_commit" yaml:"caller_link_commit" toml:"caller_link_commit"`
+	CallerAlways   *bool   `json:"caller_link_always" yaml:"caller_link_always" toml:"caller_link_always"`
+	RelativePath   *bool   `json:"relative_path" yaml:"relative_path" toml:"relative_path"`
+	BracketPadding *int    `json:"bracket_padding" yaml:"bracket_padding" toml:"bracket_padding"`
+	ColorBrackets  *bool   `json:"color_brackets" yaml:"color_brackets" toml:"color_brackets"`
+	TagStyle       *string `json:"tag_style" yaml:"tag_style" toml:"tag_style"`
+	PaddingChar    *string `json:"padding_char" yaml:"padding_char" toml:"padding_char"`
+	TagPath        *string `json:"tag_path" yaml:"tag_path" toml:"tag_path"`
+	TagSeparator   *string `json:"tag_separator" yaml:"tag_separator" toml:"tag_separator"`
+	TagColorMode   *string `json:"tag_color_mode" yaml:"tag_color_mode" toml:"tag_color_mode"`
+
+	TagColors map[string]string `json:"tag_colors" yaml:"tag_colors" toml:"tag_colors"`
+
+	Namespace        *bool `json:"namespace" yaml:"namespace" toml:"namespace"`
+	NamespacePadding *int  `json:"namespace_padding" yaml:"namespace_padding" toml:"namespace_padding"`
+
+	ErrorTree    *bool   `json:"error_tree" yaml:"error_tree" toml:"error_tree"`
+	ErrorStacks  *bool   `json:"error_stacks" yaml:"error_stacks" toml:"error_stacks"`
+	CaptureStack *bool   `json:"capture_stack" yaml:"capture_stack" toml:"capture_stack"`
+	StackLevel   *string `json:"stack_level" yaml:"stack_level" toml:"stack_level"`
+
+	Multiline       *string `json:"multiline" yaml:"multiline" toml:"multiline"`
+	MultilineGutter *string `json:"multiline_gutter" yaml:"multiline_gutter" toml:"multiline_gutter"`
+	LineWidth       *int    `json:"line_width" yaml:"line_width" toml:"line_width"`
+	Overflow        *string `json:"overflow" yaml:"overflow" toml:"overflow"`
+	NestedFields    *string `json:"nested_fields" yaml:"nested_fields" toml:"nested_fields"`
+
+	PinnedFields []string `json:"pinned_fields" yaml:"pinned_fields" toml:"pinned_fields"`
+	HiddenFields []string `json:"hidden_fields" yaml:"hidden_fields" toml:"hidden_fields"`
+	FieldOrder   *string  `json:"field_order" yaml:"field_order" toml:"field_order"`
+}
+
+// ConfigError describes a single problem found in a config file
+type ConfigError struct {
+	File string
+	Line int    // 0 when the position is unknown
+	Key  string // Dotted key path, e.g. "formatter.tag_style"; empty for syntax errors
+	Msg  string
+}
+
+func (e *ConfigError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.File)
+	if e.Line > 0 {
+		b.WriteString(":" + strconv.Itoa(e.Line))
+	}
+	if e.Key != "" {
+		b.WriteString(": " + e.Key)
+	}
+	b.WriteString(": " + e.Msg)
+	return b.String()
+}
+
+var (
+	outputTypeNames  = map[string]OutputType{"console": OutputConsole, "file": OutputFile, "multi": OutputMulti}
+	formatTypeNames  = map[string]FormatType{"raw": FormatRaw, "plain": FormatPlain, "json": FormatJSON, "json-tagged": FormatJSONTagged}
+	tagStyleNames    = map[string]TagStyle{"default": StyleDefault, "center": StyleCenter, "right": StyleRight}
+	tagPathNames     = map[string]TagPathMode{"off": TagPathOff, "joined": TagPathJoined, "columns": TagPathColumns}
+	tagColorNames    = map[string]TagColorMode{"fixed": TagColorFixed, "256": TagColor256, "truecolor": TagColorTrueColor}
+	callerPlaceNames = map[string]CallerPlacement{"below": CallerBelow, "inline": CallerInline}
+	callerPathNames  = map[string]CallerPathMode{"workdir": CallerPathWorkDir, "module": CallerPathModule, "base": CallerPathBase}
+)
+
+// LoadConfig reads and validates a config file. The format is picked from the
+// extension: .yaml, .yml, .json or .toml.
+//
+// Unknown keys and invalid values are all reported at once, each as a *ConfigError
+// joined with errors.Join.
+func LoadConfig(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(path, data)
+}
+
+func parseConfig(name string, data []byte) (*FileConfig, error) {
+	fc := &FileConfig{}
+
+	var errs []error
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".yaml", ".yml":
+		errs = decodeYAMLConfig(name, data, fc)
+	case ".json":
+		errs = decodeJSONConfig(name, data, fc)
+	case ".toml":
+		errs = decodeTOMLConfig(name, data, fc)
+	default:
+		return nil, &ConfigError{File: name, Msg: fmt.Sprintf("unsupported extension %q (want .yaml, .yml, .json or .toml)", ext)}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if _, errs := fc.validate(name); len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return fc, nil
+}
+
+// Options converts the file config into functional options for New
+func (fc *FileConfig) Options() ([]Option, error) {
+	opts, errs := fc.validate("config")
+	return opts, errors.Join(errs...)
+}
+
+func (fc *FileConfig) validate(file string) ([]Option, []error) {
+	var opts []Option
+	var errs []error
+	invalid := func(key, format string, args ...any) {
+		errs = append(errs, &ConfigError{File: file, Line: fc.lines[key], Key: key, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if fc.Level != nil {
+		if lvl, err := logrus.ParseLevel(*fc.Level); err != nil {
+			invalid("level", "unknown level %q (want trace, debug, info, warn, error, fatal or panic)", *fc.Level)
+		} else {
+			opts = append(opts, WithLevel(lvl))
+		}
+	}
+
+	if fc.Output != nil {
+		if out, ok := outputTypeNames[strings.ToLower(*fc.Output)]; !ok {
+			invalid("output", "unknown output %q (want console, file or multi)", *fc.Output)
+		} else {
+			opts = append(opts, WithOutput(out))
+		}
+	}
+
+	format := FormatPlain
+	if fc.Format != nil {
+		if f, ok := formatTypeNames[strings.ToLower(*fc.Format)]; !ok {
+			invalid("format", "unknown format %q (want raw, plain, json or json-tagged)", *fc.Format)
+		} else {
+			format = f
+			opts = append(opts, WithFormat(f))
+		}
+	}
+
+	if fc.Filename != nil {
+		opts = append(opts, WithFile(*fc.Filename))
+	}
+	if fc.Namespace != nil {
+		opts = append(opts, WithNamespace(*fc.Namespace))
+	}
+	if fc.Caller != nil {
+		showCaller := *fc.Caller
+		opts = append(opts, func(c *Config) { c.ShowCaller = showCaller })
+	}
+
+	if fc.TagLevels != nil {
+		levels := make(map[string]logrus.Level, len(fc.TagLevels))
+		for tag, name := range fc.TagLevels {
+			if lvl, err := logrus.ParseLevel(name); err != nil {
+				invalid("tag_levels."+tag, "unknown level %q", name)
+			} else {
+				levels[tag] = lvl
+			}
+		}
+		opts = append(opts, WithTagLevels(levels))
+	}
+
+	if fc.Theme != nil || fc.ThemeColors != nil {
+		theme := ThemeDark
+		if fc.Theme != nil {
+			if t, ok := LookupTheme(*fc.Theme); !ok {
+				invalid("theme", "unknown theme %q (want %s)", *fc.Theme, strings.Join(ThemeNames(), ", "))
+			} else {
+				theme = t
+			}
+		}
+		if fc.ThemeColors != nil {
+			custom := *theme
+			custom.Name = theme.Name + "+custom"
+			for role, color := range fc.ThemeColors {
+				field, ok := themeRoles[strings.ToLower(role)]
+				if !ok {
+					invalid("theme_colors."+role, "unknown color role (want %s)", strings.Join(themeRoleNames(), ", "))
+					continue
+				}
+				if _, err := ParseColor(color); err != nil {
+					invalid("theme_colors."+role, "%v", err)
+					continue
+				}
+				*field(&custom) = ThemeColor{Basic: color}
+			}
+			theme = &custom
+		}
+		opts = append(opts, func(c *Config) { c.Theme = theme })
+	}
+
+	if r := fc.Rotation; r != nil {
+		for key, v := range map[string]*int{"rotation.max_size": r.MaxSize, "rotation.max_backups": r.MaxBackups, "rotation.max_age": r.MaxAge, "rotation.max_total_size": r.MaxTotalSize} {
+			if v != nil && *v < 0 {
+				invalid(key, "must not be negative, got %d", *v)
+			}
+		}
+		var interval *RotateInterval
+		if r.Interval != nil {
+			if v, ok := rotateIntervalNames[strings.ToLower(*r.Interval)]; ok {
+				interval = &v
+			} else {
+				invalid("rotation.interval", "unknown interval %q (want none, hourly or daily)", *r.Interval)
+			}
+		}
+		if r.Pattern != nil {
+			iv := RotateNever // Checked again when the file is opened, with the final interval
+			if interval != nil {
+				iv = *interval
+			}
+			if err := checkPattern(*r.Pattern, iv); err != nil {
+				invalid("rotation.pattern", "%v", err)
+			}
+		}
+		opts = append(opts, func(c *Config) {
+			// Applied by logFileConfig on top of the other rotation settings
+			prev := c.fileRotation
+			c.fileRotation = func(rot *LogFileConfig) {
+				if prev != nil {
+					prev(rot)
+				}
+				setIfNotNil(&rot.MaxSize, r.MaxSize)
+				setIfNotNil(&rot.MaxBackups, r.MaxBackups)
+				setIfNotNil(&rot.MaxAge, r.MaxAge)
+				setIfNotNil(&rot.Compress, r.Compress)
+				setIfNotNil(&rot.Interval, interval)
+				setIfNotNil(&rot.UTC, r.UTC)
+				setIfNotNil(&rot.Pattern, r.Pattern)
+				setIfNotNil(&rot.MaxTotalSize, r.MaxTotalSize)
+			}
+		})
+	}
+
+	if fs := fc.Formatter; fs != nil {
+		if format != FormatPlain {
+			invalid("formatter", "requires format \"plain\", got %q", *fc.Format)
+		}
+
+		var callerLevel *logrus.Level
+		if fs.CallerLevel != nil {
+			if lvl, err := logrus.ParseLevel(*fs.CallerLevel); err != nil {
+				invalid("formatter.caller_level", "unknown level %q", *fs.CallerLevel)
+			} else {
+				callerLevel = &lvl
+			}
+		}
+		var stackLevel *logrus.Level
+		if fs.StackLevel != nil {
+			if lvl, err := logrus.ParseLevel(*fs.StackLevel); err != nil {
+				invalid("formatter.stack_level", "unknown level %q", *fs.StackLevel)
+			} else {
+				stackLevel = &lvl
+			}
+		}
+		var multiline *MultilineMode
+		if fs.Multiline != nil {
+			if m, ok := multilineNames[strings.ToLower(*fs.Multiline)]; !ok {
+				invalid("formatter.multiline", "unknown multiline mode %q (want indent, escape or verbatim)", *fs.Multiline)
+			} else {
+				multiline = &m
+			}
+		}
+		if fs.LineWidth != nil && *fs.LineWidth < LineWidthAuto {
+			invalid("formatter.line_width", "must be -1 (terminal width) or more, got %d", *fs.LineWidth)
+		}
+		var overflow *OverflowMode
+		if fs.Overflow != nil {
+			if m, ok := overflowNames[strings.ToLower(*fs.Overflow)]; !ok {
+				invalid("formatter.overflow", "unknown overflow mode %q (want wrap or truncate)", *fs.Overflow)
+			} else {
+				overflow = &m
+			}
+		}
+		var nested *NestedFieldMode
+		if fs.NestedFields != nil {
+			if m, ok := nestedNames[strings.ToLower(*fs.NestedFields)]; !ok {
+				invalid("formatter.nested_fields", "unknown nested fields mode %q (want flatten or json)", *fs.NestedFields)
+			} else {
+				nested = &m
+			}
+		}
+		var fieldOrder *FieldOrder
+		if fs.FieldOrder != nil {
+			if o, ok := fieldOrderNames[strings.ToLower(*fs.FieldOrder)]; !ok {
+				invalid("formatter.field_order", "unknown field order %q (want sorted or insertion)", *fs.FieldOrder)
+			} else {
+				fieldOrder = &o
+			}
+		}
+		var callerPlace *CallerPlacement
+		if fs.CallerPlace != nil {
+			if p, ok := callerPlaceNames[strings.ToLower(*fs.CallerPlace)]; !ok {
+				invalid("formatter.caller_placement", "unknown caller placement %q (want below or inline)", *fs.CallerPlace)
+			} else {
+				callerPlace = &p
+			}
+		}
+		var callerPathMode *CallerPathMode
+		if fs.CallerPath != nil {
+			if m, ok := callerPathNames[strings.ToLower(*fs.CallerPath)]; !ok {
+				invalid("formatter.caller_path", "unknown caller path %q (want workdir, module or base)", *fs.CallerPath)
+			} else {
+				callerPathMode = &m
+			}
+		}
+		var callerLink *string
+		if fs.CallerLink != nil {
+			link := *fs.CallerLink
+			if preset, ok := callerLinkNames[strings.ToLower(link)]; ok {
+				link = preset
+			} else if link != "" && !strings.Contains(link, "://") {
+				invalid("formatter.caller_link", "unknown caller link %q (want file, vscode, idea or a URL template)", link)
+			}
+			callerLink = &link
+		}
+		if fs.BracketPadding != nil && *fs.BracketPadding < 0 {
+			invalid("formatter.bracket_padding", "must not be negative, got %d", *fs.BracketPadding)
+		}
+		var tagStyle *TagStyle
+		if fs.TagStyle != nil {
+			if style, ok := tagStyleNames[strings.ToLower(*fs.TagStyle)]; !ok {
+				invalid("formatter.tag_style", "unknown tag style %q (want default, center or right)", *fs.TagStyle)
+			} else {
+				tagStyle = &style
+			}
+		}
+		var tagPath *TagPathMode
+		if fs.TagPath != nil {
+			if mode, ok := tagPathNames[strings.ToLower(*fs.TagPath)]; !ok {
+				invalid("formatter.tag_path", "unknown tag path mode %q (want off, joined or columns)", *fs.TagPath)
+			} else {
+				tagPath = &mode
+			}
+		}
+		var tagColorMode *TagColorMode
+		if fs.TagColorMode != nil {
+			if mode, ok := tagColorNames[strings.ToLower(*fs.TagColorMode)]; !ok {
+				invalid("formatter.tag_color_mode", "unknown tag color mode %q (want fixed, 256 or truecolor)", *fs.TagColorMode)
+			} else {
+				tagColorMode = &mode
+			}
+		}
+		for tag, color := range fs.TagColors {
+			if _, err := ParseColor(color); err != nil {
+				invalid("formatter.tag_colors."+tag, "%v", err)
+			}
+		}
+		if fs.NamespacePadding != nil && *fs.NamespacePadding < 0 {
+			invalid("formatter.namespace_padding", "must not be negative, got %d", *fs.NamespacePadding)
+		}
+		if fs.PaddingChar != nil && uniseg.GraphemeClusterCount(*fs.PaddingChar) != 1 {
+			invalid("formatter.padding_char", "must be a single character, got %q", *fs.PaddingChar)
+		}
+
+		opts = append(opts, func(c *Config) {
+			f := newLoggerFormatter()
+			setIfNotNil(&f.UseColors, fs.Colors)
+			setIfNotNil(&f.ShowTimestamp, fs.Timestamp)
+			setIfNotNil(&f.ShowCaller, fs.Caller)
+			setIfNotNil(&f.CallerLevel, callerLevel)
+			setIfNotNil(&f.CallerFormat, fs.CallerFormat)
+			setIfNotNil(&f.CallerPlacement, callerPlace)
+			setIfNotNil(&f.CallerPath, callerPathMode)
+			setIfNotNil(&f.CallerLink, callerLink)
+			setIfNotNil(&f.CallerLinkCommit, fs.CallerCommit)
+			setIfNotNil(&f.CallerLinkAlways, fs.CallerAlways)
+			setIfNotNil(&f.UseRelativePath, fs.RelativePath)
+			setIfNotNil(&f.BracketPadding, fs.BracketPadding)
+			setIfNotNil(&f.ColorBrackets, fs.ColorBrackets)
+			setIfNotNil(&f.TagStyle, tagStyle)
+			setIfNotNil(&f.PaddingChar, fs.PaddingChar)
+			setIfNotNil(&f.TagPath, tagPath)
+			setIfNotNil(&f.TagSeparator, fs.TagSeparator)
+			setIfNotNil(&f.TagColorMode, tagColorMode)
+			if fs.TagColors != nil {
+				f.TagColors = fs.TagColors
+			}
+			setIfNotNil(&f.ShowNamespace, fs.Namespace)
+			setIfNotNil(&f.NamespacePadding, fs.NamespacePadding)
+			setIfNotNil(&f.ErrorTree, fs.ErrorTree)
+			setIfNotNil(&f.ErrorStacks, fs.ErrorStacks)
+			setIfNotNil(&f.CaptureStack, fs.CaptureStack)
+			setIfNotNil(&f.StackLevel, stackLevel)
+			setIfNotNil(&f.Multiline, multiline)
+			setIfNotNil(&f.MultilineGutter, fs.MultilineGutter)
+			setIfNotNil(&f.LineWidth, fs.LineWidth)
+			setIfNotNil(&f.Overflow, overflow)
+			setIfNotNil(&f.NestedFields, nested)
+			setIfNotNil(&f.FieldOrder, fieldOrder)
+			if fs.PinnedFields != nil {
+				f.PinnedFields = fs.PinnedFields
+			}
+			if fs.HiddenFields != nil {
+				f.HiddenFields = fs.HiddenFields
+			}
+			c.CustomFormat = f
+
+			// Colors and caller follow the final output and caller settings,
+			// which options after this one may still change
+			c.resolvers = append(c.resolvers, func(c *Config) {
+				if c.CustomFormat != f {
+					return // Replaced by a later option
+				}
+				resolved := *f
+				if fs.Colors == nil {
+					resolved.UseColors = false
+					if c.getOutput() == OutputConsole {
+						resolved.UseColors, _ = DetectColors(os.Stdout)
+					}
+				}
+				if fs.Caller == nil {
+					resolved.ShowCaller = c.ShowCaller
+				}
+				c.CustomFormat = &resolved
+			})
+		})
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		a, b := errs[i].(*ConfigError), errs[j].(*ConfigError)
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Key < b.Key
+	})
+	return opts, errs
+}
+
+func setIfNotNil[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// --- Decoders ---
+
+func decodeYAMLConfig(file string, data []byte, fc *FileConfig) []error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return yamlErrors(file, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil // Empty file
+	}
+
+	root := doc.Content[0]
+	fc.lines = map[string]int{}
+	if errs := checkYAMLKeys(file, root, reflect.TypeOf(*fc), "", fc.lines); len(errs) > 0 {
+		return errs
+	}
+	if err := root.Decode(fc); err != nil {
+		return yamlErrors(file, err)
+	}
+	return nil
+}
+
+func checkYAMLKeys(file string, n *yaml.Node, t reflect.Type, prefix string, lines map[string]int) []error {
+	if n.Kind != yaml.MappingNode {
+		return nil // Type mismatches are reported by Decode
+	}
+
+	var errs []error
+	keys := configKeys(t, "yaml")
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		key := prefix + k.Value
+		lines[key] = k.Line
+
+		ft, ok := keys[k.Value]
+		if !ok {
+			errs = append(errs, &ConfigError{File: file, Line: k.Line, Key: key, Msg: "unknown key"})
+			continue
+		}
+		switch {
+		case ft.Kind() == reflect.Struct:
+			errs = append(errs, checkYAMLKeys(file, v, ft, key+".", lines)...)
+		case ft.Kind() == reflect.Map && v.Kind == yaml.MappingNode:
+			for j := 0; j+1 < len(v.Content); j += 2 {
+				lines[key+"."+v.Content[j].Value] = v.Content[j].Line
+			}
+		}
+	}
+	return errs
+}
+
+var yamlLineRegex = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+func yamlErrors(file string, err error) []error {
+	msgs := []string{strings.TrimPrefix(err.Error(), "yaml: ")}
+	var te *yaml.TypeError
+	if errors.As(err, &te) {
+		msgs = te.Errors
+	}
+
+	errs := make([]error, 0, len(msgs))
+	for _, msg := range msgs {
+		ce := &ConfigError{File: file, Msg: msg}
+		if m := yamlLineRegex.FindStringSubmatch(msg); m != nil {
+			ce.Line, _ = strconv.Atoi(m[1])
+			ce.Msg = m[2]
+		}
+		errs = append(errs, ce)
+	}
+	return errs
+}
+
+func decodeJSONConfig(file string, data []byte, fc *FileConfig) []error {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []error{jsonError(file, data, err)}
+	}
+	fc.lines = jsonKeyLines(data)
+	if errs := checkJSONKeys(file, raw, reflect.TypeOf(*fc), "", fc.lines); len(errs) > 0 {
+		return errs
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(fc); err != nil {
+		return []error{jsonError(file, data, err)}
+	}
+	return nil
+}
+
+func checkJSONKeys(file string, raw map[string]any, t reflect.Type, prefix string, lines map[string]int) []error {
+	names := make([]string, 0, len(raw))
+	for k := range raw {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	keys := configKeys(t, "json")
+	for _, k := range names {
+		ft, ok := keys[k]
+		if !ok {
+			errs = append(errs, &ConfigError{File: file, Line: lines[prefix+k], Key: prefix + k, Msg: "unknown key"})
+			continue
+		}
+		if sub, isMap := raw[k].(map[string]any); isMap && ft.Kind() == reflect.Struct {
+			errs = append(errs, checkJSONKeys(file, sub, ft, prefix+k+".", lines)...)
+		}
+	}
+	return errs
+}
+
+// jsonKeyLines maps the dotted path of every object key in valid JSON data
+// to its line
+func jsonKeyLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var value func(prefix string) error
+	value = func(prefix string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				tok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := tok.(string)
+				lines[prefix+key] = lineAt(data, dec.InputOffset())
+				if err := value(prefix + key + "."); err != nil {
+					return err
+				}
+			}
+		case json.Delim('['):
+			for dec.More() {
+				if err := value(prefix); err != nil {
+					return err
+				}
+			}
+		default:
+			return nil
+		}
+		_, err = dec.Token() // Closing delimiter
+		return err
+	}
+	value("")
+	return lines
+}
+
+func jsonError(file string, data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &ConfigError{File: file, Line: lineAt(data, syntaxErr.Offset), Msg: syntaxErr.Error()}
+	case errors.As(err, &typeErr):
+		return &ConfigError{
+			File: file,
+			Line: lineAt(data, typeErr.Offset),
+			Key:  typeErr.Field,
+			Msg:  fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+		}
+	default:
+		return &ConfigError{File: file, Msg: err.Error()}
+	}
+}
+
+// lineAt returns the 1-based line number of a byte offset
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+var tomlErrorRegex = regexp.MustCompile(`^toml: (?:line (\d+) ?)?(?:\(last key "([^"]*)"\))?:? (.*)$`)
+
+func decodeTOMLConfig(file string, data []byte, fc *FileConfig) []error {
+	md, err := toml.Decode(string(data), fc)
+	if err != nil {
+		// Parse and type errors share the "toml: line N (last key "k"): msg" layout
+		ce := &ConfigError{File: file, Msg: err.Error()}
+		if m := tomlErrorRegex.FindStringSubmatch(err.Error()); m != nil {
+			ce.Line, _ = strconv.Atoi(m[1])
+			ce.Key, ce.Msg = m[2], m[3]
+		}
+		return []error{ce}
+	}
+
+	fc.lines = tomlKeyLines(data)
+	var errs []error
+	for _, key := range md.Undecoded() {
+		errs = append(errs, &ConfigError{File: file, Line: fc.lines[key.String()], Key: key.String(), Msg: "unknown key"})
+	}
+	return errs
+}
+
+// tomlKeyLines maps the dotted path of every "key = value" line and table
+// header in data to its line. Keys inside inline tables and multi-line
+// values are not found.
+func tomlKeyLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	table := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "[["):
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				continue
+			}
+			table = tomlKey(line[1:end])
+			if _, seen := lines[table]; !seen {
+				lines[table] = i + 1
+			}
+		default:
+			key, _, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			path := tomlKey(key)
+			if table != "" {
+				path = table + "." + path
+			}
+			if _, seen := lines[path]; !seen {
+				lines[path] = i + 1
+			}
+		}
+	}
+	return lines
+}
+
+// tomlKey normalizes a dotted TOML key, dropping spaces and quotes
+func tomlKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(p), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// configKeys maps the keys accepted at one level of FileConfig to their field types
+func configKeys(t reflect.Type, tag string) map[string]reflect.Type {
+	keys := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "" {
+			continue
+		}
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		keys[name] = ft
+	}
+	return keys
+}
@@ -0,0 +1,478 @@
+package pretty
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const yamlConfig = `level: debug
+output: file
+format: plain
+filename: service.log
+namespace: App
+caller: false
+rotation:
+  max_size: 20
+  compress: false
+formatter:
+  colors: false
+  timestamp: true
+  caller_level: error
+  bracket_padding: 12
+  tag_style: right
+  padding_char: "-"
+`
+
+const jsonConfig = `{
+  "level": "debug",
+  "output": "file",
+  "format": "plain",
+  "filename": "service.log",
+  "namespace": "App",
+  "caller": false,
+  "rotation": {"max_size": 20, "compress": false},
+  "formatter": {
+    "colors": false,
+    "timestamp": true,
+    "caller_level": "error",
+    "bracket_padding": 12,
+    "tag_style": "right",
+    "padding_char": "-"
+  }
+}`
+
+const tomlConfig = `level = "debug"
+output = "file"
+format = "plain"
+filename = "service.log"
+namespace = "App"
+caller = false
+
+[rotation]
+max_size = 20
+compress = false
+
+[formatter]
+colors = false
+timestamp = true
+caller_level = "error"
+bracket_padding = 12
+tag_style = "right"
+padding_char = "-"
+`
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfig_AllFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"logging.yaml", yamlConfig},
+		{"logging.yml", yamlConfig},
+		{"logging.json", jsonConfig},
+		{"logging.toml", tomlConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc, err := LoadConfig(writeConfig(t, tt.name, tt.content))
+			if err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+
+			opts, err := fc.Options()
+			if err != nil {
+				t.Fatalf("Options failed: %v", err)
+			}
+
+			cfg := &Config{}
+			for _, opt := range opts {
+				opt(cfg)
+			}
+			cfg.resolve()
+
+			if cfg.Level == nil || *cfg.Level != logrus.DebugLevel {
+				t.Errorf("Expected debug level, got %v", cfg.Level)
+			}
+			if cfg.Output == nil || *cfg.Output != OutputFile {
+				t.Errorf("Expected file output, got %v", cfg.Output)
+			}
+			if cfg.Filename != "service.log" || cfg.Namespace != "App" || cfg.ShowCaller {
+				t.Errorf("Unexpected persistence settings: %+v", cfg)
+			}
+
+			rot := cfg.logFileConfig()
+			if rot.MaxSize != 20 || rot.Compress || rot.MaxBackups != 5 {
+				t.Errorf("Expected rotation overrides on top of defaults, got %+v", rot)
+			}
+
+			f := cfg.CustomFormat
+			if f == nil {
+				t.Fatal("Expected formatter section to produce a CustomFormat")
+			}
+			if f.UseColors || !f.ShowTimestamp || f.CallerLevel != logrus.ErrorLevel {
+				t.Errorf("Unexpected formatter flags: %+v", f)
+			}
+			if f.BracketPadding != 12 || f.TagStyle != StyleRight || f.PaddingChar != "-" {
+				t.Errorf("Unexpected tag settings: %+v", f)
+			}
+			if !f.ColorBrackets || !f.UseRelativePath {
+				t.Errorf("Expected unset keys to keep NewCustomFormatter defaults: %+v", f)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_UnknownKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"bad.yaml", "level: info\ncolour: true\nformatter:\n  tag_styel: right\n", []string{
+			"bad.yaml:2: colour: unknown key",
+			"bad.yaml:4: formatter.tag_styel: unknown key",
+		}},
+		{"bad.json", "{\n  \"colour\": true,\n  \"formatter\": {\n    \"tag_styel\": \"right\"\n  }\n}\n", []string{
+			"bad.json:2: colour: unknown key",
+			"bad.json:4: formatter.tag_styel: unknown key",
+		}},
+		{"bad.toml", "colour = true\n[formatter]\ntag_styel = \"right\"\n", []string{
+			"bad.toml:1: colour: unknown key",
+			"bad.toml:3: formatter.tag_styel: unknown key",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig(tt.name, []byte(tt.content))
+			if err == nil {
+				t.Fatal("Expected error for unknown keys")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected %q in error, got: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_InvalidValues(t *testing.T) {
+	content := `level: loud
+output: printer
+formatter:
+  bracket_padding: -1
+  tag_style: diagonal
+  padding_char: "ab"
+rotation:
+  max_age: -3
+`
+	_, err := parseConfig("bad.yaml", []byte(content))
+	if err == nil {
+		t.Fatal("Expected validation errors")
+	}
+
+	for _, want := range []string{
+		`bad.yaml:1: level: unknown level "loud"`,
+		`bad.yaml:2: output: unknown output "printer"`,
+		`bad.yaml:4: formatter.bracket_padding: must not be negative, got -1`,
+		`bad.yaml:5: formatter.tag_style: unknown tag style "diagonal"`,
+		`bad.yaml:6: formatter.padding_char: must be a single character`,
+		`bad.yaml:8: rotation.max_age: must not be negative, got -3`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in error, got: %v", want, err)
+		}
+	}
+
+	var ce *ConfigError
+	if !errors.As(err, &ce) {
+		t.Error("Expected errors to unwrap to *ConfigError")
+	}
+}
+
+func TestLoadConfig_TypeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"types.yaml", "formatter:\n  bracket_padding: wide\n", "types.yaml:2: "},
+		{"types.json", "{\n  \"formatter\": {\n    \"bracket_padding\": \"wide\"\n  }\n}", "types.json:3: formatter.bracket_padding: expected int"},
+		{"types.toml", "[formatter]\nbracket_padding = \"wide\"\n", "types.toml:2: "},
+		{"syntax.json", "{\n  \"level\": \n}", "syntax.json:3: "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseConfig(tt.name, []byte(tt.content))
+			if err == nil {
+				t.Fatal("Expected type error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected %q in error, got: %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_FormatterRequiresPlain(t *testing.T) {
+	_, err := parseConfig("c.yaml", []byte("format: json\nformatter:\n  colors: true\n"))
+	if err == nil || !strings.Contains(err.Error(), `c.yaml:2: formatter: requires format "plain", got "json"`) {
+		t.Errorf("Expected formatter/format conflict error, got: %v", err)
+	}
+}
+
+func TestLoadConfig_UnsupportedExtension(t *testing.T) {
+	if _, err := parseConfig("logging.ini", []byte("level=info")); err == nil {
+		t.Error("Expected error for unsupported extension")
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	if _, err := LoadConfig(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected not-exist error, got: %v", err)
+	}
+}
+
+func TestLoadConfig_Empty(t *testing.T) {
+	fc, err := parseConfig("empty.yaml", nil)
+	if err != nil {
+		t.Fatalf("Expected empty config to load, got: %v", err)
+	}
+	if opts, _ := fc.Options(); len(opts) != 0 {
+		t.Errorf("Expected no options from empty config, got %d", len(opts))
+	}
+}
+
+func TestFormatterFileConfig_ColorsFollowOutput(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("output: file\nformatter:\n  tag_style: center\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	cfg.resolve()
+	if cfg.CustomFormat.UseColors {
+		t.Error("Expected colors to default off for file output")
+	}
+}
+
+func TestWithConfigFile_ResolvedAfterAllOptions(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+	path := writeConfig(t, "logging.yaml", "formatter:\n  tag_style: center\nrotation:\n  max_backups: 0\n")
+	logFile := filepath.Join(t.TempDir(), "app.log")
+
+	for _, opts := range [][]Option{
+		{WithConfigFile(path), WithOutput(OutputFile), WithFile(logFile), WithoutCaller()},
+		{WithOutput(OutputFile), WithFile(logFile), WithoutCaller(), WithConfigFile(path)},
+	} {
+		cfg := newConfig(opts...)
+		cfg.resolve()
+		if f := cfg.CustomFormat; f.UseColors || f.ShowCaller || f.TagStyle != StyleCenter {
+			t.Errorf("Expected file output and no caller whatever the option order, got %+v", f)
+		}
+		if rot := cfg.logFileConfig(); rot.MaxBackups != 0 || rot.MaxSize != 10 {
+			t.Errorf("Expected max_backups 0 from the file over the defaults, got %+v", rot)
+		}
+	}
+
+	cfg := newConfig(WithConfigFile(path), WithFileRotation(LogFileConfig{MaxBackups: 2}))
+	if rot := cfg.logFileConfig(); rot.MaxBackups != 2 {
+		t.Errorf("Expected WithFileRotation after the file to win, got %+v", rot)
+	}
+}
+
+func TestFormatterFileConfig_TagPath(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("formatter:\n  tag_path: columns\n  tag_separator: /\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.CustomFormat.TagPath != TagPathColumns || cfg.CustomFormat.TagSeparator != "/" {
+		t.Errorf("Expected columns tag path with / separator, got %v %q", cfg.CustomFormat.TagPath, cfg.CustomFormat.TagSeparator)
+	}
+
+	if _, err := parseConfig("c.yaml", []byte("formatter:\n  tag_path: tree\n")); err == nil || !strings.Contains(err.Error(), "formatter.tag_path") {
+		t.Errorf("Expected tag_path error, got %v", err)
+	}
+}
+
+func TestFormatterFileConfig_TagColors(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("formatter:\n  tag_color_mode: 256\n  tag_colors:\n    DB: cyan\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.CustomFormat.TagColorMode != TagColor256 || cfg.CustomFormat.TagColors["DB"] != "cyan" {
+		t.Errorf("Expected 256 mode with DB override, got %v %v", cfg.CustomFormat.TagColorMode, cfg.CustomFormat.TagColors)
+	}
+
+	_, err = parseConfig("c.yaml", []byte("formatter:\n  tag_colors:\n    DB: sparkly\n"))
+	if err == nil || !strings.Contains(err.Error(), "c.yaml:3: formatter.tag_colors.DB") {
+		t.Errorf("Expected tag color error with line, got %v", err)
+	}
+}
+
+func TestFileConfig_Theme(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("theme: solarized\ntheme_colors:\n  info: \"#00af5f\"\n  field_key: 244\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.Theme == nil || cfg.Theme.Name != "solarized+custom" {
+		t.Fatalf("Expected customized solarized theme, got %+v", cfg.Theme)
+	}
+	if cfg.Theme.Info.Basic != "#00af5f" || cfg.Theme.FieldKey.Basic != "244" || cfg.Theme.Warn != ThemeSolarized.Warn {
+		t.Errorf("Expected overrides on top of solarized, got %+v", cfg.Theme)
+	}
+
+	_, err = parseConfig("c.yaml", []byte("theme: neon\ntheme_colors:\n  sparkle: red\n"))
+	if err == nil || !strings.Contains(err.Error(), "c.yaml:1: theme: unknown theme") || !strings.Contains(err.Error(), "c.yaml:3: theme_colors.sparkle") {
+		t.Errorf("Expected theme errors with lines, got %v", err)
+	}
+}
+
+func TestFormatterFileConfig_Caller(t *testing.T) {
+	fc, err := parseConfig("c.toml", []byte("[formatter]\ncaller_format = \"{pkg}.{func}\"\ncaller_placement = \"inline\"\ncaller_path = \"module\"\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	f := cfg.CustomFormat
+	if f.CallerFormat != "{pkg}.{func}" || f.CallerPlacement != CallerInline || f.CallerPath != CallerPathModule {
+		t.Errorf("Unexpected caller settings: %q %v %v", f.CallerFormat, f.CallerPlacement, f.CallerPath)
+	}
+
+	if _, err := parseConfig("c.yaml", []byte("formatter:\n  caller_placement: above\n")); err == nil || !strings.Contains(err.Error(), "formatter.caller_placement") {
+		t.Errorf("Expected caller_placement error, got %v", err)
+	}
+}
+
+func TestFormatterFileConfig_Multiline(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("formatter:\n  multiline: escape\n  multiline_gutter: \"│ \"\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.CustomFormat.Multiline != MultilineEscape || cfg.CustomFormat.MultilineGutter != "│ " {
+		t.Errorf("Expected escape mode with gutter, got %v %q", cfg.CustomFormat.Multiline, cfg.CustomFormat.MultilineGutter)
+	}
+
+	if _, err := parseConfig("c.yaml", []byte("formatter:\n  multiline: fold\n")); err == nil || !strings.Contains(err.Error(), "formatter.multiline") {
+		t.Errorf("Expected multiline error, got %v", err)
+	}
+}
+
+func TestFormatterFileConfig_LineWidth(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("formatter:\n  line_width: -1\n  overflow: truncate\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.CustomFormat.LineWidth != LineWidthAuto || cfg.CustomFormat.Overflow != OverflowTruncate {
+		t.Errorf("Expected auto width with truncation, got %d %v", cfg.CustomFormat.LineWidth, cfg.CustomFormat.Overflow)
+	}
+
+	_, err = parseConfig("c.yaml", []byte("formatter:\n  line_width: -5\n  overflow: scroll\n"))
+	if err == nil || !strings.Contains(err.Error(), "formatter.line_width") || !strings.Contains(err.Error(), "formatter.overflow") {
+		t.Errorf("Expected line_width and overflow errors, got %v", err)
+	}
+}
+
+func TestRotationFileConfig_TimeBased(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("rotation:\n  interval: hourly\n  utc: true\n  pattern: \"svc-%Y%m%d%H.log\"\n  max_total_size: 100\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	rot := cfg.logFileConfig()
+	if rot.Interval != RotateHourly || !rot.UTC || rot.Pattern != "svc-%Y%m%d%H.log" || rot.MaxTotalSize != 100 || rot.MaxSize != 10 {
+		t.Errorf("Expected hourly UTC rotation on top of the defaults, got %+v", rot)
+	}
+
+	_, err = parseConfig("c.yaml", []byte("rotation:\n  interval: weekly\n  pattern: logs/app.log\n"))
+	if err == nil || !strings.Contains(err.Error(), "rotation.interval") || !strings.Contains(err.Error(), "rotation.pattern") {
+		t.Errorf("Expected interval and pattern errors, got %v", err)
+	}
+}
+
+func TestNew_WithConfigFile(t *testing.T) {
+	path := writeConfig(t, "logging.yaml", "level: warn\noutput: file\nfilename: "+filepath.Join(t.TempDir(), "app.log")+"\n")
+
+	logger := New(WithConfigFile(path))
+
+	if logger.GetLevel() != logrus.WarnLevel {
+		t.Errorf("Expected warn level from file, got %v", logger.GetLevel())
+	}
+	if _, ok := logger.Out.(*lumberjack.Logger); !ok {
+		t.Error("Expected file output from config file")
+	}
+}
+
+func TestNew_WithConfigFile_LaterOptionsWin(t *testing.T) {
+	path := writeConfig(t, "logging.yaml", "level: warn\n")
+
+	logger := New(WithConfigFile(path), WithLevel(logrus.DebugLevel))
+
+	if logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected later option to override file, got %v", logger.GetLevel())
+	}
+}
+
+func TestNew_WithConfigFile_InvalidKeepsDefaults(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+	path := writeConfig(t, "logging.yaml", "level: loud\n")
+
+	logger := New(WithConfigFile(path))
+
+	if logger.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected defaults when the config file is invalid, got %v", logger.GetLevel())
+	}
+}
@@ -0,0 +1,294 @@
+package pretty
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// maxStackFrames limits the frames printed for one stack trace
+const maxStackFrames = 32
+
+// maxErrorDepth stops unwrapping errors whose chain never ends
+const maxErrorDepth = 32
+
+// WithErrorTree renders the error field (logrus.ErrorKey) under the log line, with
+// wrapped and joined errors as an indented tree. Errors without a chain or stack
+// stay inline.
+func WithErrorTree(enabled bool) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.ErrorTree = enabled
+	}
+}
+
+// WithErrorStacks prints the stack trace carried by errors such as those of
+// github.com/pkg/errors below the error tree
+func WithErrorStacks(enabled bool) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.ErrorStacks = enabled
+	}
+}
+
+// WithCapturedStack prints the stack of the logging goroutine for entries at
+// level and above (e.g. ErrorLevel), unless their error already carries one
+func WithCapturedStack(enabled bool, level logrus.Level) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.CaptureStack = enabled
+		f.StackLevel = level
+	}
+}
+
+// errorNode is one error of a chain with the part of the message it adds
+type errorNode struct {
+	kind     string
+	message  string // The part added by this error, e.g. "load user"
+	full     string // err.Error()
+	children []*errorNode
+}
+
+// buildErrorTree unwraps err through Unwrap() error and Unwrap() []error
+func buildErrorTree(err error, depth int) *errorNode {
+	node := &errorNode{kind: fmt.Sprintf("%T", err), message: err.Error(), full: err.Error()}
+	if depth >= maxErrorDepth {
+		return node
+	}
+
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := u.Unwrap(); inner != nil {
+			// "load user: connection refused" wrapping "connection refused" adds "load user"
+			if own, ok := strings.CutSuffix(node.message, ": "+inner.Error()); ok {
+				node.message = own
+			}
+			node.children = []*errorNode{buildErrorTree(inner, depth+1)}
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range u.Unwrap() {
+			if inner != nil {
+				node.children = append(node.children, buildErrorTree(inner, depth+1))
+			}
+		}
+		if strings.Contains(node.message, "\n") {
+			node.message = strconv.Itoa(len(node.children)) + " errors" // errors.Join puts one per line
+		}
+	}
+	return node
+}
+
+// flatten lists the node and its descendants depth-first
+func (n *errorNode) flatten(out []*errorNode) []*errorNode {
+	out = append(out, n)
+	for _, c := range n.children {
+		out = c.flatten(out)
+	}
+	return out
+}
+
+// errorStack returns the stack carried by the innermost error of the chain that
+// has one. Supported are StackTrace() of github.com/pkg/errors and compatible
+// packages, and Callers() []uintptr as in github.com/go-errors/errors.
+func errorStack(err error) []runtime.Frame {
+	var pcs []uintptr
+	for i := 0; err != nil && i < maxErrorDepth; i++ {
+		if p := stackPCs(err); p != nil {
+			pcs = p
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			err = u.Unwrap()
+		case interface{ Unwrap() []error }:
+			// Joined errors have no single origin; use the first one that carries a stack
+			for _, inner := range u.Unwrap() {
+				if frames := errorStack(inner); frames != nil {
+					return frames
+				}
+			}
+			err = nil
+		default:
+			err = nil
+		}
+	}
+	if pcs == nil {
+		return nil
+	}
+	return framesOf(pcs, false)
+}
+
+// stackPCs returns the program counters carried by err itself, if any
+func stackPCs(err error) []uintptr {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		return c.Callers()
+	}
+
+	// pkg/errors returns its own StackTrace type, a slice of uintptr-based Frames
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Type().Out(0)
+	if out.Kind() != reflect.Slice || out.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	trace := m.Call(nil)[0]
+	pcs := make([]uintptr, trace.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(trace.Index(i).Uint())
+	}
+	return pcs
+}
+
+// packagePrefix is the prefix of function names in this package
+var packagePrefix = reflect.TypeOf(CustomFormatter{}).PkgPath() + "."
+
+// captureStack returns the stack of the calling goroutine, starting at the
+// function that logged
+func captureStack() []runtime.Frame {
+	pcs := make([]uintptr, maxStackFrames+16)
+	n := runtime.Callers(2, pcs)
+	return framesOf(pcs[:n], true)
+}
+
+// framesOf resolves pcs into at most maxStackFrames frames without runtime
+// internals. With skipLogging, leading frames of logrus and this package are dropped.
+func framesOf(pcs []uintptr, skipLogging bool) []runtime.Frame {
+	var out []runtime.Frame
+	frames := runtime.CallersFrames(pcs)
+	for len(out) < maxStackFrames {
+		frame, more := frames.Next()
+		logging := strings.HasPrefix(frame.Function, "github.com/sirupsen/logrus.") ||
+			(strings.HasPrefix(frame.Function, packagePrefix) && !strings.HasSuffix(frame.File, "_test.go"))
+		switch {
+		case skipLogging && len(out) == 0 && logging:
+		case strings.HasPrefix(frame.Function, "runtime."):
+		default:
+			out = append(out, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// entryError returns the error stored under logrus.ErrorKey, if any
+func entryError(entry *logrus.Entry) error {
+	err, _ := entry.Data[logrus.ErrorKey].(error)
+	return err
+}
+
+// errorTree returns the chain of err when it is worth more than an inline
+// field: it wraps other errors, or a stack is shown with it
+func (f *CustomFormatter) errorTree(err error, stack []runtime.Frame) *errorNode {
+	if err == nil || !f.ErrorTree {
+		return nil
+	}
+	root := buildErrorTree(err, 0)
+	if len(root.children) == 0 && len(stack) == 0 {
+		return nil
+	}
+	return root
+}
+
+// writeErrorTree writes the error chain and the stack below the log line,
+// indented to the message column. Either may be nil.
+func (f *CustomFormatter) writeErrorTree(b *strings.Builder, root *errorNode, stack []runtime.Frame, indent string) {
+	p := f.palette()
+	color := func(code, s string) string {
+		if f.UseColors && code != "" {
+			return code + s + ColorReset
+		}
+		return s
+	}
+
+	if root != nil {
+		message := root.full
+		if strings.Contains(message, "\n") {
+			message = root.message // "2 errors" for errors.Join, which are listed below
+		}
+		b.WriteString("\n" + indent + color(p.level(logrus.ErrorLevel), "error:") + " " + message)
+		if len(root.children) > 0 {
+			b.WriteString(" " + color(p.caller, "["+root.kind+"]"))
+		}
+		var walk func(n *errorNode, prefix string)
+		walk = func(n *errorNode, prefix string) {
+			for i, c := range n.children {
+				branch, next := "├─ ", "│  "
+				if i == len(n.children)-1 {
+					branch, next = "└─ ", "   "
+				}
+				b.WriteString("\n" + indent + color(p.caller, prefix+branch) + c.message + " " + color(p.caller, "["+c.kind+"]"))
+				walk(c, prefix+next)
+			}
+		}
+		walk(root, "")
+	}
+
+	if len(stack) > 0 {
+		b.WriteString("\n" + indent + color(p.caller, "stack:"))
+		for _, frame := range stack {
+			pkg, fn := splitFunction(frame.Function)
+			if pkg != "" {
+				fn = pkg + "." + fn
+			}
+			b.WriteString("\n" + indent + "   " + color(p.caller, "at "+fn+" ("+f.callerFilePath(frame.File)+":"+strconv.Itoa(frame.Line)+")"))
+		}
+	}
+}
+
+// errorStackFor picks the stack shown for an entry: the one carried by its error,
+// or the goroutine's when capturing is enabled for the level
+func errorStackFor(entry *logrus.Entry, err error, carried, capture bool, level logrus.Level) []runtime.Frame {
+	if carried && err != nil {
+		if frames := errorStack(err); frames != nil {
+			return frames
+		}
+	}
+	if capture && entry.Level <= level {
+		return captureStack()
+	}
+	return nil
+}
+
+// JSONError is the "error" object written by JSONFormatter with StructuredErrors
+type JSONError struct {
+	Kind    string           `json:"kind"`
+	Message string           `json:"message"`
+	Chain   []JSONErrorLink  `json:"chain,omitempty"`
+	Stack   []JSONStackFrame `json:"stack,omitempty"`
+}
+
+// JSONErrorLink is one wrapped or joined error below the top one
+type JSONErrorLink struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// JSONStackFrame is one frame of a stack trace
+type JSONStackFrame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// newJSONError builds the structured form of err
+func newJSONError(err error, stack []runtime.Frame, relative bool) JSONError {
+	je := JSONError{Kind: fmt.Sprintf("%T", err), Message: err.Error()}
+	nodes := buildErrorTree(err, 0).flatten(nil)
+	for _, n := range nodes[1:] {
+		je.Chain = append(je.Chain, JSONErrorLink{Kind: n.kind, Message: n.message})
+	}
+	je.Stack = jsonStack(stack, relative)
+	return je
+}
+
+func jsonStack(stack []runtime.Frame, relative bool) []JSONStackFrame {
+	var out []JSONStackFrame
+	for _, frame := range stack {
+		out = append(out, JSONStackFrame{Func: frame.Function, File: callerPath(frame.File, relative), Line: frame.Line})
+	}
+	return out
+}
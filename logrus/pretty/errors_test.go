@@ -0,0 +1,152 @@
+package pretty
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// stackError mimics github.com/pkg/errors: a StackTrace method returning a
+// named slice of uintptr-based frames
+type stackFrame uintptr
+type stackTrace []stackFrame
+
+type stackError struct {
+	msg   string
+	stack []uintptr
+}
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	return &stackError{msg: msg, stack: pcs[:n]}
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) StackTrace() stackTrace {
+	trace := make(stackTrace, len(e.stack))
+	for i, pc := range e.stack {
+		trace[i] = stackFrame(pc)
+	}
+	return trace
+}
+
+func TestErrorTree_WrapAndJoin(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithCaller(false, 0), WithErrorTree(true), WithErrorStacks(true))
+	root := errors.New("connection refused")
+	err := fmt.Errorf("load user: %w", errors.Join(fmt.Errorf("query: %w", root), errors.New("cache miss")))
+
+	out := formatEntry(t, f, logrus.ErrorLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: err})
+	indent := strings.Repeat(" ", 23) // The message column
+	want := "ERROR  [DB]            query failed\n" +
+		indent + "error: load user [*fmt.wrapError]\n" +
+		indent + "└─ 2 errors [*errors.joinError]\n" +
+		indent + "   ├─ query [*fmt.wrapError]\n" +
+		indent + "   │  └─ connection refused [*errors.errorString]\n" +
+		indent + "   └─ cache miss [*errors.errorString]\n"
+	if out != want {
+		t.Errorf("Expected the error as a tree below the line\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestErrorTree_FullMessageOnFirstLine(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithCaller(false, 0), WithErrorTree(true), WithErrorStacks(true))
+	err := fmt.Errorf("load user: %w", fmt.Errorf("query: %w", errors.New("connection refused")))
+
+	out := formatEntry(t, f, logrus.ErrorLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: err})
+	if !strings.Contains(out, "error: load user: query: connection refused [*fmt.wrapError]\n") {
+		t.Errorf("Expected the full message on the first line, got:\n%s", out)
+	}
+}
+
+func TestErrorTree_PlainErrorStaysInline(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithCaller(false, 0), WithErrorTree(true), WithErrorStacks(true))
+	out := formatEntry(t, f, logrus.ErrorLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: errors.New("boom")})
+	if out != "ERROR  [DB]            query failed error=boom\n" {
+		t.Errorf("Expected an inline error field, got %q", out)
+	}
+
+	f = NewCustomFormatter(WithColors(false), WithCaller(false, 0), WithErrorTree(false))
+	out = formatEntry(t, f, logrus.ErrorLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: fmt.Errorf("wrap: %w", errors.New("boom"))})
+	if out != "ERROR  [DB]            query failed error=\"wrap: boom\"\n" {
+		t.Errorf("Expected an inline error field with the tree disabled, got %q", out)
+	}
+}
+
+func TestErrorTree_CarriedStack(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithCaller(false, 0), WithErrorTree(true), WithErrorStacks(true), WithCallerPath(CallerPathBase))
+	err := fmt.Errorf("load user: %w", newStackError("not found"))
+
+	out := formatEntry(t, f, logrus.WarnLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: err})
+	if !strings.Contains(out, "stack:\n") || !strings.Contains(out, "at pretty.TestErrorTree_CarriedStack (errors_test.go:") {
+		t.Errorf("Expected the carried stack, got:\n%s", out)
+	}
+
+	f.ErrorStacks = false
+	if out := formatEntry(t, f, logrus.WarnLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: err}); strings.Contains(out, "stack:") {
+		t.Errorf("Expected no stack with ErrorStacks off, got:\n%s", out)
+	}
+}
+
+func TestErrorTree_CapturedStack(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithCaller(false, 0), WithErrorTree(true), WithErrorStacks(true), WithCallerPath(CallerPathBase), WithCapturedStack(true, logrus.ErrorLevel))
+
+	out := formatEntry(t, f, logrus.ErrorLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: errors.New("boom")})
+	lines := strings.Split(out, "\n")
+	if len(lines) < 3 || !strings.HasSuffix(lines[1], "error: boom") || !strings.HasSuffix(lines[2], "stack:") {
+		t.Fatalf("Expected the error and a captured stack, got:\n%s", out)
+	}
+	if !strings.Contains(lines[3], "at pretty.formatEntry (formatters_test.go:") {
+		t.Errorf("Expected the stack to start at the logging function, got %q", lines[3])
+	}
+
+	if out := formatEntry(t, f, logrus.WarnLevel, "[DB] query failed", logrus.Fields{logrus.ErrorKey: errors.New("boom")}); strings.Contains(out, "stack:") {
+		t.Errorf("Expected no captured stack below StackLevel, got:\n%s", out)
+	}
+}
+
+func TestJSONFormatter_StructuredError(t *testing.T) {
+	err := fmt.Errorf("load user: %w", newStackError("not found"))
+	entry := logrus.NewEntry(logrus.New()).WithError(err)
+	entry.Message = "failed"
+	entry.Level = logrus.ErrorLevel
+
+	out := formatJSON(t, NewJSONFormatter(), entry)
+	obj, ok := out["error"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected an error object, got %v", out["error"])
+	}
+	if obj["kind"] != "*fmt.wrapError" || obj["message"] != "load user: not found" {
+		t.Errorf("Unexpected kind or message: %v", obj)
+	}
+	chain, _ := obj["chain"].([]any)
+	if len(chain) != 1 || chain[0].(map[string]any)["kind"] != "*pretty.stackError" {
+		t.Errorf("Expected the wrapped error in the chain, got %v", obj["chain"])
+	}
+	stack, _ := obj["stack"].([]any)
+	if len(stack) == 0 || stack[0].(map[string]any)["func"] != packagePrefix+"TestJSONFormatter_StructuredError" {
+		t.Errorf("Expected the carried stack, got %v", obj["stack"])
+	}
+}
+
+func TestJSONFormatter_CapturedStackWithoutError(t *testing.T) {
+	f := NewJSONFormatter()
+	f.CaptureStack = true
+
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "failed"
+	entry.Level = logrus.ErrorLevel
+	if out := formatJSON(t, f, entry); out["stack"] == nil {
+		t.Errorf("Expected a captured stack, got %v", out)
+	}
+
+	entry.Level = logrus.InfoLevel
+	if out := formatJSON(t, f, entry); out["stack"] != nil {
+		t.Errorf("Expected no stack for info, got %v", out["stack"])
+	}
+}
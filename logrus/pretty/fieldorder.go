@@ -0,0 +1,134 @@
+package pretty
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FieldOrderKey is the field in which Ordered records the order of its keys.
+// Loggers created by New or Child take it out of the fields before any other
+// hook or formatter runs; CustomFormatter and JSONFormatter never write it.
+const FieldOrderKey = "_field_order"
+
+// FieldOrder defines the order of fields after the pinned ones
+type FieldOrder int
+
+const (
+	FieldOrderSorted    FieldOrder = iota // Alphabetical
+	FieldOrderInsertion                   // As passed to Ordered, the others sorted after them
+)
+
+// fieldOrderNames are the orders accepted by name in config files
+var fieldOrderNames = map[string]FieldOrder{
+	"sorted":    FieldOrderSorted,
+	"insertion": FieldOrderInsertion,
+}
+
+// Ordered builds fields from key-value pairs and remembers their order for
+// FieldOrderInsertion, since logrus.Fields is a map:
+//
+//	log.WithFields(pretty.Ordered("method", "GET", "path", "/users", "status", 200)).Info("[HTTP] done")
+//
+// Keys that are not strings are formatted with %v; a missing last value is nil.
+func Ordered(keysAndValues ...any) logrus.Fields {
+	fields := make(logrus.Fields, len(keysAndValues)/2+1)
+	order := make([]string, 0, len(keysAndValues)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		var v any
+		if i+1 < len(keysAndValues) {
+			v = keysAndValues[i+1]
+		}
+		if _, seen := fields[key]; !seen {
+			order = append(order, key)
+		}
+		fields[key] = v
+	}
+	fields[FieldOrderKey] = order
+	return fields
+}
+
+// WithPinnedFields shows the given keys first, in this order, e.g.
+// "request_id", "user", "status". The remaining fields follow.
+func WithPinnedFields(keys ...string) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.PinnedFields = keys
+	}
+}
+
+// WithHiddenFields leaves the given keys out of console output. "user.email"
+// hides a single value of a flattened field. Files and JSON output keep them.
+func WithHiddenFields(keys ...string) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.HiddenFields = keys
+	}
+}
+
+// WithFieldOrder sets the order of the fields that are not pinned
+func WithFieldOrder(order FieldOrder) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.FieldOrder = order
+	}
+}
+
+// fieldOrderOf returns the key order Ordered recorded for the fields of e
+func fieldOrderOf(e *logrus.Entry) []string {
+	if m := metaOf(e); m != nil {
+		return m.order
+	}
+	order, _ := e.Data[FieldOrderKey].([]string)
+	return order
+}
+
+// orderedKeys returns the keys of data to write: pinned keys first, then the
+// rest sorted or in the given insertion order
+func (f *CustomFormatter) orderedKeys(data logrus.Fields, order []string) []string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		if k != FieldOrderKey && !slices.Contains(f.PinnedFields, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	if order != nil && f.FieldOrder == FieldOrderInsertion {
+		rank := make(map[string]int, len(order))
+		for i, k := range order {
+			rank[k] = i
+		}
+		sort.SliceStable(keys, func(i, j int) bool {
+			ri, iok := rank[keys[i]]
+			rj, jok := rank[keys[j]]
+			if iok && jok {
+				return ri < rj
+			}
+			return iok && !jok // Ordered keys first, the others stay sorted
+		})
+	}
+
+	pinned := make([]string, 0, len(f.PinnedFields))
+	for _, k := range f.PinnedFields {
+		if _, ok := data[k]; ok && !slices.Contains(pinned, k) {
+			pinned = append(pinned, k)
+		}
+	}
+	return append(pinned, keys...)
+}
+
+// hidden reports whether a rendered field key is hidden, either itself or
+// through the field it was flattened from
+func (f *CustomFormatter) hidden(key string) bool {
+	for _, h := range f.HiddenFields {
+		if key == h || strings.HasPrefix(key, h+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFieldOrder_Pinned(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithPinnedFields("request_id", "status", "missing"))
+	got := strings.Join(f.fieldTokens(logrus.Fields{"b": 2, "status": 200, "a": 1, "request_id": "r1"}, nil), " ")
+	if got != "request_id=r1 status=200 a=1 b=2" {
+		t.Errorf("Expected pinned fields first, got %s", got)
+	}
+}
+
+func TestFieldOrder_Insertion(t *testing.T) {
+	fields := Ordered("path", "/users", "method", "GET", "status", 200)
+	fields["extra"] = true
+	order := fieldOrderOf(&logrus.Entry{Data: fields})
+
+	f := NewCustomFormatter(WithColors(false), WithFieldOrder(FieldOrderInsertion))
+	if got := strings.Join(f.fieldTokens(fields, order), " "); got != "path=/users method=GET status=200 extra=true" {
+		t.Errorf("Expected insertion order, then the rest, got %s", got)
+	}
+
+	f = NewCustomFormatter(WithColors(false))
+	if got := strings.Join(f.fieldTokens(fields, order), " "); got != "extra=true method=GET path=/users status=200" {
+		t.Errorf("Expected sorted order by default without the order key, got %s", got)
+	}
+
+	f = NewCustomFormatter(WithColors(false), WithFieldOrder(FieldOrderInsertion), WithPinnedFields("status"))
+	if got := strings.Join(f.fieldTokens(fields, order), " "); got != "status=200 path=/users method=GET extra=true" {
+		t.Errorf("Expected pinned before insertion order, got %s", got)
+	}
+}
+
+func TestOrdered_OddAndDuplicateKeys(t *testing.T) {
+	fields := Ordered("a", 1, "b", 2, "a", 3, "c")
+	if fields["a"] != 3 || fields["c"] != nil {
+		t.Errorf("Unexpected values: %v", fields)
+	}
+	if order := fields[FieldOrderKey].([]string); strings.Join(order, ",") != "a,b,c" {
+		t.Errorf("Expected the first position of a duplicate key, got %v", order)
+	}
+}
+
+func TestFieldOrder_Hidden(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithHiddenFields("trace_id", "user.email"))
+	got := strings.Join(f.fieldTokens(logrus.Fields{
+		"trace_id": "abc",
+		"user":     map[string]string{"name": "bob", "email": "bob@example.com"},
+		"ok":       true,
+	}, nil), " ")
+	if got != "ok=true user.name=bob" {
+		t.Errorf("Expected hidden fields left out, got %s", got)
+	}
+}
+
+func TestFieldOrder_HiddenOnConsoleOnly(t *testing.T) {
+	custom := NewCustomFormatter(WithColors(false), WithHiddenFields("trace_id"))
+	mw := NewMultiWriter(MultiWriterWithFormattersConfig{customFormat: custom})
+	mw.AddWriter(os.Stdout, false, false)
+	mw.AddWriter(&bytes.Buffer{}, false, false)
+
+	entry := logrus.NewEntry(logrus.New()).WithField("trace_id", "abc")
+	entry.Message = "hello"
+	entry.Level = logrus.InfoLevel
+	console, _ := mw.pairs[0].f.Format(entry)
+	if strings.Contains(string(console), "trace_id") {
+		t.Errorf("Expected trace_id hidden on the console, got %q", console)
+	}
+	file, _ := mw.pairs[1].f.Format(entry)
+	if !strings.Contains(string(file), "trace_id=abc") {
+		t.Errorf("Expected trace_id in other writers, got %q", file)
+	}
+
+	logger := New(WithOutput(OutputFile), WithFile(filepath.Join(t.TempDir(), "app.log")), WithCustomFormat(*custom))
+	if f := logger.Formatter.(*CustomFormatter); f.HiddenFields != nil {
+		t.Errorf("Expected no hidden fields for file output, got %v", f.HiddenFields)
+	}
+}
+
+func TestJSONFormatter_SkipsFieldOrder(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(Ordered("a", 1))
+	entry.Message = "x"
+	b, err := NewJSONFormatter().Format(entry)
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", b, err)
+	}
+	if _, ok := out[FieldOrderKey]; ok || out["a"] != 1.0 {
+		t.Errorf("Expected only the fields, got %v", out)
+	}
+}
+
+func TestNew_FieldOrderNotWritten(t *testing.T) {
+	for _, format := range []FormatType{FormatJSON, FormatRaw} {
+		var buf, sink bytes.Buffer
+		logger := New(WithFormat(format), WithSink(NewSink(&sink, &logrus.JSONFormatter{})))
+		logger.SetOutput(&buf)
+
+		logger.WithFields(Ordered("method", "GET", "status", 200)).Info("done")
+		for _, out := range []string{buf.String(), sink.String()} {
+			if strings.Contains(out, FieldOrderKey) || !strings.Contains(out, "GET") {
+				t.Errorf("Format %d: expected the fields without %s, got %q", format, FieldOrderKey, out)
+			}
+		}
+	}
+}
+
+func TestNew_FieldOrderInsertion(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(OutputConsole), WithoutCaller(), WithCustomFormat(*NewCustomFormatter(WithColors(false), WithFieldOrder(FieldOrderInsertion))))
+	logger.SetOutput(&buf)
+
+	logger.WithFields(Ordered("path", "/users", "method", "GET")).Info("[HTTP] done")
+	if out := buf.String(); !strings.Contains(out, "path=/users method=GET") || strings.Contains(out, FieldOrderKey) {
+		t.Errorf("Expected the fields in insertion order, got %q", out)
+	}
+}
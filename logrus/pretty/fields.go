@@ -0,0 +1,233 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NestedFieldMode defines how maps and structs in field values are written
+type NestedFieldMode int
+
+const (
+	NestedFlatten NestedFieldMode = iota // user.id=7 user.name=bob
+	NestedJSON                           // user={"id":7,"name":"bob"}
+)
+
+// nestedNames are the modes accepted by name in config files
+var nestedNames = map[string]NestedFieldMode{
+	"flatten": NestedFlatten,
+	"json":    NestedJSON,
+}
+
+// maxFlattenDepth is the nesting level below which values are written as JSON
+const maxFlattenDepth = 4
+
+// FieldRenderer renders field values of types the formatter does not know,
+// e.g. a Money type as "12.50 EUR". RenderField reports false to leave the
+// value to the next renderer or the built-in rendering. The result is quoted
+// when it needs to be. Nested values are passed with their dotted key.
+type FieldRenderer interface {
+	RenderField(key string, value any) (string, bool)
+}
+
+// FieldRendererFunc adapts a function to FieldRenderer
+type FieldRendererFunc func(key string, value any) (string, bool)
+
+// RenderField implements FieldRenderer
+func (fn FieldRendererFunc) RenderField(key string, value any) (string, bool) {
+	return fn(key, value)
+}
+
+// WithFieldRenderer adds a renderer for custom field types. Renderers are
+// asked in the order they were added.
+func WithFieldRenderer(r FieldRenderer) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.FieldRenderers = append(f.FieldRenderers, r)
+	}
+}
+
+// WithNestedFields sets how maps and structs in field values are written
+func WithNestedFields(mode NestedFieldMode) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.NestedFields = mode
+	}
+}
+
+// valueKind picks the theme color of a rendered value
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindBool
+	kindTime
+	kindNil
+)
+
+// field is one rendered key=value pair
+type field struct {
+	key   string
+	value string
+	kind  valueKind
+}
+
+// renderField renders a value, flattening maps and structs into one field per leaf
+func (f *CustomFormatter) renderField(out []field, key string, v any, depth int) []field {
+	for _, r := range f.FieldRenderers {
+		if s, ok := r.RenderField(key, v); ok {
+			return append(out, field{key, quoteValue(s), kindString})
+		}
+	}
+
+	if v == nil {
+		return append(out, field{key, "nil", kindNil})
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return append(out, field{key, "nil", kindNil}) // Before calling methods that may not expect nil
+	}
+
+	switch x := v.(type) {
+	case time.Time:
+		return append(out, field{key, x.Format(time.RFC3339Nano), kindTime})
+	case time.Duration:
+		return append(out, field{key, x.String(), kindTime})
+	case error:
+		return append(out, field{key, quoteValue(x.Error()), kindString})
+	case fmt.Stringer:
+		return append(out, field{key, quoteValue(x.String()), kindString})
+	case string:
+		return append(out, field{key, quoteValue(x), kindString})
+	case []byte:
+		if utf8.Valid(x) {
+			return append(out, field{key, quoteValue(string(x)), kindString})
+		}
+	}
+
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return append(out, field{key, "nil", kindNil})
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return append(out, field{key, strconv.FormatBool(rv.Bool()), kindBool})
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return append(out, field{key, fmt.Sprint(rv.Interface()), kindNumber})
+	case reflect.String:
+		return append(out, field{key, quoteValue(rv.String()), kindString})
+	case reflect.Map, reflect.Struct:
+		if f.NestedFields == NestedFlatten && depth < maxFlattenDepth {
+			return f.flatten(out, key, rv, depth)
+		}
+		return append(out, field{key, quoteValue(compactJSON(rv.Interface())), kindString})
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return append(out, field{key, "nil", kindNil})
+		}
+		return append(out, field{key, quoteValue(compactJSON(rv.Interface())), kindString})
+	}
+	return append(out, field{key, quoteValue(fmt.Sprint(v)), kindString})
+}
+
+// flatten writes the entries of a map or the exported fields of a struct as
+// "key.child" fields, in sorted or declaration order
+func (f *CustomFormatter) flatten(out []field, key string, rv reflect.Value, depth int) []field {
+	if rv.Kind() == reflect.Map {
+		if rv.Len() == 0 {
+			return append(out, field{key, "{}", kindString})
+		}
+		keys := rv.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = fmt.Sprint(k.Interface())
+		}
+		order := make([]int, len(keys))
+		for i := range order {
+			order[i] = i
+		}
+		sort.Slice(order, func(a, b int) bool { return names[order[a]] < names[order[b]] })
+		for _, i := range order {
+			out = f.renderField(out, key+"."+names[i], rv.MapIndex(keys[i]).Interface(), depth+1)
+		}
+		return out
+	}
+
+	before := len(out)
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag, _, _ := strings.Cut(sf.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fv := rv.Field(i)
+		if sf.Anonymous && fv.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			out = f.flatten(out, key, fv, depth) // Embedded structs share the parent's keys
+			continue
+		}
+		out = f.renderField(out, key+"."+name, fv.Interface(), depth+1)
+	}
+	if len(out) == before {
+		return append(out, field{key, "{}", kindString})
+	}
+	return out
+}
+
+// compactJSON encodes v without HTML escaping, falling back to %v
+func compactJSON(v any) string {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// quoteValue quotes s the way logfmt expects: when it is empty or contains
+// spaces, '=', quotes or control characters
+func quoteValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// value returns the color of a field value of the given kind
+func (p *palette) value(kind valueKind) string {
+	switch kind {
+	case kindNumber:
+		return p.fieldNumber
+	case kindBool:
+		return p.fieldBool
+	case kindTime:
+		return p.fieldTime
+	case kindNil:
+		return p.fieldNil
+	default:
+		return p.fieldValue
+	}
+}
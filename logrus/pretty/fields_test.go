@@ -0,0 +1,134 @@
+package pretty
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type address struct {
+	City string `json:"city"`
+	Zip  string `json:"-"`
+}
+
+type account struct {
+	ID      int
+	Name    string `json:"name,omitempty"`
+	Tags    []string
+	address `json:"-"`
+	Home    address
+	secret  string
+}
+
+type money struct {
+	cents    int
+	currency string
+}
+
+func renderFields(f *CustomFormatter, v any) string {
+	return strings.Join(f.fieldTokens(logrus.Fields{"v": v}, nil), " ")
+}
+
+func TestFieldValues_Quoting(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false))
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{"plain", "v=plain"},
+		{"with space", `v="with space"`},
+		{"a=b", `v="a=b"`},
+		{`say "hi"`, `v="say \"hi\""`},
+		{"line\nbreak", `v="line\nbreak"`},
+		{"", `v=""`},
+		{"ünïcode", "v=ünïcode"},
+		{[]byte("raw bytes"), `v="raw bytes"`},
+		{errors.New("not found"), `v="not found"`},
+		{nil, "v=nil"},
+		{(*account)(nil), "v=nil"},
+		{42, "v=42"},
+		{3.5, "v=3.5"},
+		{true, "v=true"},
+		{1500 * time.Millisecond, "v=1.5s"},
+		{time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC), "v=2026-10-16T09:30:00Z"},
+		{[]int{1, 2}, "v=[1,2]"},
+		{[]string{"a b"}, `v="[\"a b\"]"`},
+	}
+	for _, tt := range tests {
+		if got := renderFields(f, tt.in); got != tt.want {
+			t.Errorf("%#v: got %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFieldValues_Flatten(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false))
+	acc := account{ID: 7, Name: "bob", Tags: []string{"admin"}, Home: address{City: "Oslo", Zip: "0150"}, secret: "x"}
+	acc.address = address{City: "Bergen"}
+
+	got := renderFields(f, &acc)
+	want := `v.ID=7 v.name=bob v.Tags="[\"admin\"]" v.Home.city=Oslo`
+	if got != want {
+		t.Errorf("Struct:\ngot  %s\nwant %s", got, want)
+	}
+
+	got = renderFields(f, map[string]any{"b": map[string]int{"x": 1}, "a": "y z"})
+	if got != `v.a="y z" v.b.x=1` {
+		t.Errorf("Map: got %s", got)
+	}
+	if got := renderFields(f, map[string]int{}); got != "v={}" {
+		t.Errorf("Empty map: got %s", got)
+	}
+}
+
+func TestFieldValues_JSON(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithNestedFields(NestedJSON))
+	got := renderFields(f, map[string]any{"id": 7, "name": "bob"})
+	if got != `v="{\"id\":7,\"name\":\"bob\"}"` {
+		t.Errorf("Expected quoted compact JSON, got %s", got)
+	}
+}
+
+func TestFieldValues_Renderer(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithFieldRenderer(FieldRendererFunc(func(key string, v any) (string, bool) {
+		m, ok := v.(money)
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency), true
+	})))
+
+	got := strings.Join(f.fieldTokens(logrus.Fields{"price": money{1250, "EUR"}, "order": map[string]any{"total": money{99, "USD"}}}, nil), " ")
+	if got != `order.total="0.99 USD" price="12.50 EUR"` {
+		t.Errorf("Expected the renderer for top-level and nested values, got %s", got)
+	}
+}
+
+func TestFieldValues_TypeColors(t *testing.T) {
+	f := NewCustomFormatter()
+	p := f.palette()
+	tokens := f.fieldTokens(logrus.Fields{"n": 1, "b": false, "d": time.Second, "z": nil, "s": "x"}, nil)
+
+	want := []string{
+		p.fieldKey + "b=" + p.fieldBool + "false" + ColorReset,
+		p.fieldKey + "d=" + p.fieldTime + "1s" + ColorReset,
+		p.fieldKey + "n=" + p.fieldNumber + "1" + ColorReset,
+		p.fieldKey + "s=" + p.fieldValue + "x" + ColorReset,
+		p.fieldKey + "z=" + p.fieldNil + "nil" + ColorReset,
+	}
+	if strings.Join(tokens, " ") != strings.Join(want, " ") {
+		t.Errorf("Unexpected colors:\ngot  %q\nwant %q", tokens, want)
+	}
+	if p.fieldNumber == p.fieldValue || p.fieldBool == p.fieldValue {
+		t.Errorf("Expected the dark theme to color numbers and bools apart from strings")
+	}
+
+	mono := NewCustomFormatter(WithTheme(ThemeMonochrome)).palette()
+	if mono.fieldNumber != mono.fieldValue {
+		t.Errorf("Expected unset type colors to fall back to the value color")
+	}
+}
@@ -5,7 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"sort"
+	"slices"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -14,6 +14,8 @@ import (
 // Pre-compiled regexes for performance
 var (
 	bracketRegex     = regexp.MustCompile(`\[(.*?)\]`)
+	nextTagRegex     = regexp.MustCompile(`^\s*\[(.*?)\]`)
+	ansiRegex        = regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]|\x1b\]8;[^\x1b]*\x1b\\`) // Colors and OSC 8 hyperlinks
 	parenthesesRegex = regexp.MustCompile(`\((.*?)\)`)
 )
 
@@ -26,6 +28,18 @@ const (
 	StyleRight                   // [Auth ••••]     - Right-aligned with decorative padding
 )
 
+// TagPathMode defines how a run of leading tags such as "[API][Users]" is rendered
+type TagPathMode int
+
+const (
+	TagPathOff     TagPathMode = iota // [API] [Users] created - Only the first tag is styled
+	TagPathJoined                     // [API›Users]    created - One tag with the path joined
+	TagPathColumns                    // [API] [Users]  created - One aligned column per level
+)
+
+// DefaultTagSeparator joins tag path levels in TagPathJoined mode
+const DefaultTagSeparator = "›"
+
 type CustomFormatter struct {
 	UseColors     bool
 	ShowCaller    bool
@@ -52,6 +66,75 @@ type CustomFormatter struct {
 	// PaddingChar defines the character used for tag decoration
 	// Common choices: "=", "-", "·", "•". Default: "•"
 	PaddingChar string
+	// ShowNamespace renders the logger namespace (e.g. "Main/Auth") as a column
+	// between the level and the tag. Entries without a namespace are unaffected.
+	ShowNamespace bool
+	// NamespacePadding sets the minimum width of the namespace column. Default: 10
+	NamespacePadding int
+	// CallerFormat is the caller template, e.g. CallerFormatShort. See the
+	// CallerFormat constants for placeholders. Default: CallerFormatDefault
+	CallerFormat string
+	// CallerPlacement puts the caller on its own line (default) or at the end of the line
+	CallerPlacement CallerPlacement
+	// CallerPath picks how {path} is written: relative to the working directory
+	// (default), relative to the module's go.mod, or the file name only
+	CallerPath CallerPathMode
+	// CallerLink makes the caller a clickable OSC 8 hyperlink, e.g. CallerLinkVSCode
+	// or CallerLinkRepo(url). Only used together with UseColors, on terminals
+	// DetectHyperlinks accepts.
+	CallerLink string
+	// CallerLinkAlways writes CallerLink without asking DetectHyperlinks
+	CallerLinkAlways bool
+	// CallerLinkCommit fills {commit} in CallerLink. Default: the VCS revision of the build
+	CallerLinkCommit string
+	// TagPath treats consecutive leading tags as a path, e.g. "[API][Users]".
+	// TagPathOff (default) styles only the first tag and leaves the rest in the message.
+	TagPath TagPathMode
+	// TagSeparator joins path levels in TagPathJoined mode. Default: "›"
+	TagSeparator string
+	// TagColumns is the number of columns reserved in TagPathColumns mode, each
+	// BracketPadding wide. Longer paths add columns for that entry. Default: 2
+	TagColumns int
+	// TagColorMode picks the tag color: the theme's tag color for every tag (default), or a
+	// stable 256-color or truecolor per tag
+	TagColorMode TagColorMode
+	// TagColors sets explicit colors per tag, e.g. {"DB": "cyan", "HTTP": "#ff8700"}
+	TagColors map[string]string
+	// Theme sets the colors of levels, tags, namespace, fields and caller. Default: ThemeDark
+	Theme *Theme
+	// ColorDepth limits the theme to 16 colors, 256 colors or truecolor. Default: ColorDepth256
+	ColorDepth ColorDepth
+	// ErrorTree moves the error field below the line when it wraps other errors,
+	// showing the chain from errors.Unwrap and errors.Join as a tree
+	ErrorTree bool
+	// ErrorStacks shows the stack trace carried by errors, e.g. from github.com/pkg/errors
+	ErrorStacks bool
+	// CaptureStack shows the stack of the logging goroutine for entries at
+	// StackLevel and above whose error carries none
+	CaptureStack bool
+	// StackLevel is the minimum level for CaptureStack. Default: ErrorLevel
+	StackLevel logrus.Level
+	// Multiline picks how messages with newlines are written: verbatim (default),
+	// with continuation lines indented to the message column, or escaped
+	Multiline MultilineMode
+	// MultilineGutter is put in front of indented continuation lines, e.g. "│ "
+	MultilineGutter string
+	// LineWidth limits lines to this many cells; LineWidthAuto uses the terminal
+	// width. Default: 0, no limit. Only applied to console output by New.
+	LineWidth int
+	// Overflow wraps long lines at the message column (default), or drops the
+	// fields that don't fit
+	Overflow OverflowMode
+	// NestedFields writes maps and structs as dotted keys (default) or compact JSON
+	NestedFields NestedFieldMode
+	// FieldRenderers render values of custom types, see FieldRenderer
+	FieldRenderers []FieldRenderer
+	// PinnedFields are written first, in this order, e.g. "request_id", "user"
+	PinnedFields []string
+	// HiddenFields are left out. Only applied to console output by New.
+	HiddenFields []string
+	// FieldOrder sorts the other fields (default) or keeps the order of Ordered
+	FieldOrder FieldOrder
 }
 
 // FormatterOption is a functional option for configuring CustomFormatter
@@ -140,9 +223,32 @@ func WithCenterBrackets(enabled bool, char string) FormatterOption {
 	}
 }
 
+// WithNamespaceColumn shows the logger namespace as a column padded to the given width
+func WithNamespaceColumn(enabled bool, padding int) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.ShowNamespace = enabled
+		f.NamespacePadding = max(0, padding)
+	}
+}
+
+// WithTagPath renders a run of leading tags as a hierarchical path
+//
+// Examples for "[API][Users] created":
+//   - TagPathJoined:  [API›Users]     created
+//   - TagPathColumns: [API]           [Users]         created
+//
+// The separator is only used by TagPathJoined; empty means "›".
+func WithTagPath(mode TagPathMode, separator string) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.TagPath = mode
+		f.TagSeparator = separator
+	}
+}
+
 // NewCustomFormatter creates a new formatter with the given options
 func NewCustomFormatter(opts ...FormatterOption) *CustomFormatter {
 	// Defaults: colors on, timestamps off, caller on for Warn and above, relative paths, 15 char bracket padding, colored brackets, default tag style
+	// The namespace column, error trees and multi-line indentation are opt-in, see New for a formatter with all of them
 	f := &CustomFormatter{
 		UseColors:       true,
 		ShowCaller:      true,
@@ -153,6 +259,7 @@ func NewCustomFormatter(opts ...FormatterOption) *CustomFormatter {
 		ColorBrackets:   true,
 		TagStyle:        StyleDefault,
 		PaddingChar:     "•",
+		StackLevel:      logrus.ErrorLevel,
 	}
 
 	for _, opt := range opts {
@@ -162,6 +269,18 @@ func NewCustomFormatter(opts ...FormatterOption) *CustomFormatter {
 	return f
 }
 
+// newLoggerFormatter returns NewCustomFormatter with the features the plain
+// format of New turns on: the namespace column, error trees with carried
+// stacks and indented multi-line messages
+func newLoggerFormatter() *CustomFormatter {
+	return NewCustomFormatter(
+		WithNamespaceColumn(true, 10),
+		WithErrorTree(true),
+		WithErrorStacks(true),
+		WithMultiline(MultilineIndent, ""),
+	)
+}
+
 const (
 	ColorReset       = "\033[0m"
 	ColorRed         = "\033[31m"
@@ -190,24 +309,7 @@ func (f *CustomFormatter) formatCommon(entry *logrus.Entry) (timestamp, level, m
 	message = entry.Message
 
 	if f.UseColors {
-		switch entry.Level {
-		case logrus.PanicLevel:
-			colorCode = ColorMagenta
-		case logrus.TraceLevel:
-			colorCode = ColorCyan
-		case logrus.DebugLevel:
-			colorCode = ColorCyan
-		case logrus.InfoLevel:
-			colorCode = ColorGreen
-		case logrus.WarnLevel:
-			colorCode = ColorYellow
-		case logrus.ErrorLevel:
-			colorCode = ColorRed
-		case logrus.FatalLevel:
-			colorCode = ColorMagenta
-		default:
-			colorCode = ColorReset
-		}
+		colorCode = f.palette().level(entry.Level)
 		resetCode = ColorReset
 	}
 
@@ -220,39 +322,52 @@ func (f *CustomFormatter) formatCallerInfo(entry *logrus.Entry) string {
 		return ""
 	}
 
-	filePath := entry.Caller.File
-
-	// Use absolute or relative path based on configuration
-	if !f.UseRelativePath {
-		// Get absolute path
-		absFile, err := filepath.Abs(filePath)
-		if err == nil {
-			filePath = absFile
-		}
-	} else {
-		// Get relative path from current working directory
-		if wd, err := os.Getwd(); err == nil {
-			if relPath, err := filepath.Rel(wd, filePath); err == nil {
-				filePath = relPath
-			}
+	format := f.CallerFormat
+	if format == "" {
+		format = CallerFormatDefault
+		if f.CallerPlacement == CallerInline {
+			format = CallerFormatInline
 		}
 	}
+	callerInfo := f.expandCaller(format, entry.Caller)
 
-	callerInfo := fmt.Sprintf("└─ at (%s:%d)", filePath, entry.Caller.Line)
-
-	// Apply color to parentheses content if colors are enabled
+	// Apply color to parentheses content if colors are enabled, or to all of it
+	// when the template has none
 	if f.UseColors {
-		callerInfo = parenthesesRegex.ReplaceAllStringFunc(callerInfo, func(bracketed string) string {
-			return fmt.Sprintf("%s%s%s", ColorVeryDimGray, bracketed, ColorReset)
-		})
+		color := f.palette().caller
+		if parenthesesRegex.MatchString(callerInfo) {
+			callerInfo = parenthesesRegex.ReplaceAllStringFunc(callerInfo, func(bracketed string) string {
+				return fmt.Sprintf("%s%s%s", color, bracketed, ColorReset)
+			})
+		} else {
+			callerInfo = color + callerInfo + ColorReset
+		}
+		if f.CallerLink != "" && (f.CallerLinkAlways || DetectHyperlinks()) {
+			callerInfo = hyperlink(f.callerURL(entry.Caller), callerInfo)
+		}
 	}
 
 	return callerInfo
 }
 
-func (f *CustomFormatter) coloredTagWithPadding(inner string, maxPadding int, style TagStyle) string {
-	tagColor := ColorYellow
-	padColor := ColorVeryDimGray
+// callerPath returns file as an absolute path, or relative to the working directory
+func callerPath(file string, relative bool) string {
+	if !relative {
+		if absFile, err := filepath.Abs(file); err == nil {
+			return absFile
+		}
+		return file
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if relPath, err := filepath.Rel(wd, file); err == nil {
+			return relPath
+		}
+	}
+	return file
+}
+
+func (f *CustomFormatter) coloredTagWithPadding(inner, tagColor string, maxPadding int, style TagStyle) string {
+	padColor := f.palette().tagPadding
 
 	fill := f.PaddingChar
 	if fill == "" {
@@ -262,10 +377,10 @@ func (f *CustomFormatter) coloredTagWithPadding(inner string, maxPadding int, st
 	switch style {
 	case StyleCenter:
 		availableSpace := maxPadding - 2
-		if len(inner) >= availableSpace-2 {
+		if displayWidth(inner) >= availableSpace-2 {
 			return tagColor + "[" + inner + "]" + ColorReset
 		}
-		totalDots := availableSpace - len(inner) - 2
+		totalDots := availableSpace - displayWidth(inner) - 2
 		leftDots := totalDots / 2
 		rightDots := totalDots - leftDots
 
@@ -273,23 +388,23 @@ func (f *CustomFormatter) coloredTagWithPadding(inner string, maxPadding int, st
 		b.WriteString(tagColor)
 		b.WriteByte('[')
 		b.WriteString(padColor)
-		b.WriteString(strings.Repeat(fill, leftDots))
+		b.WriteString(fillRun(fill, leftDots))
 		b.WriteString(tagColor)
 		b.WriteByte(' ')
 		b.WriteString(inner)
 		b.WriteByte(' ')
 		b.WriteString(padColor)
-		b.WriteString(strings.Repeat(fill, rightDots))
+		b.WriteString(fillRun(fill, rightDots))
 		b.WriteString(tagColor)
 		b.WriteByte(']')
 		b.WriteString(ColorReset)
 		return b.String()
 	case StyleRight:
 		availableSpace := maxPadding - 2
-		if len(inner) >= availableSpace-1 {
+		if displayWidth(inner) >= availableSpace-1 {
 			return tagColor + "[" + inner + "]" + ColorReset
 		}
-		totalDots := availableSpace - len(inner) - 1
+		totalDots := availableSpace - displayWidth(inner) - 1
 
 		var b strings.Builder
 		b.WriteString(tagColor)
@@ -297,7 +412,7 @@ func (f *CustomFormatter) coloredTagWithPadding(inner string, maxPadding int, st
 		b.WriteString(inner)
 		b.WriteByte(']')
 		b.WriteString(padColor)
-		b.WriteString(strings.Repeat(fill, totalDots))
+		b.WriteString(fillRun(fill, totalDots))
 		b.WriteString(tagColor)
 		b.WriteByte(' ')
 		b.WriteString(ColorReset)
@@ -312,11 +427,11 @@ func (f *CustomFormatter) coloredTagWithPadding(inner string, maxPadding int, st
 // Example: "Auth" -> "[•• Auth ••]" (assuming maxPadding=15)
 func (f *CustomFormatter) centerTag(inner string, maxPadding int) string {
 	availableSpace := maxPadding - 2 // Subtract 2 for the brackets
-	if len(inner) >= availableSpace-2 {
+	if displayWidth(inner) >= availableSpace-2 {
 		return "[" + inner + "]" // Not enough space, return as-is
 	}
 
-	totalDots := availableSpace - len(inner) - 2 // 2 spaces around the text
+	totalDots := availableSpace - displayWidth(inner) - 2 // 2 spaces around the text
 	leftDots := totalDots / 2
 	rightDots := totalDots - leftDots
 
@@ -326,9 +441,9 @@ func (f *CustomFormatter) centerTag(inner string, maxPadding int) string {
 	}
 
 	return fmt.Sprintf("[%s %s %s]",
-		strings.Repeat(fill, leftDots),
+		fillRun(fill, leftDots),
 		inner,
-		strings.Repeat(fill, rightDots))
+		fillRun(fill, rightDots))
 }
 
 // rightPadTag right-aligns a tag within the available space with decorative padding
@@ -336,50 +451,201 @@ func (f *CustomFormatter) centerTag(inner string, maxPadding int) string {
 // Example: "Auth" -> "[Auth]••••" (assuming maxPadding=15)
 func (f *CustomFormatter) rightPadTag(inner string, maxPadding int) string {
 	availableSpace := maxPadding - 2 // Subtract 2 for the brackets
-	if len(inner) >= availableSpace-1 {
+	if displayWidth(inner) >= availableSpace-1 {
 		return "[" + inner + "]" // Not enough space, return as-is
 	}
 
-	totalDots := availableSpace - len(inner) - 1 // 1 space before the dots
+	totalDots := availableSpace - displayWidth(inner) - 1 // 1 space before the dots
 	fill := f.PaddingChar
 	if fill == "" {
 		fill = "•"
 	}
 
-	return fmt.Sprintf("[%s]%s ", inner, strings.Repeat(fill, totalDots))
+	return fmt.Sprintf("[%s]%s ", inner, fillRun(fill, totalDots))
 }
 
-// appendFields sorts and appends structured data to the log line
-func (f *CustomFormatter) appendFields(b *strings.Builder, data logrus.Fields) {
-	// 1. Sort the keys for consistent output across runs
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+// fieldTokens orders the structured data and renders each field as "key=value".
+// order is the insertion order recorded by Ordered, if any.
+func (f *CustomFormatter) fieldTokens(data logrus.Fields, order []string) []string {
+	// 1. Pinned keys first, the rest sorted for consistent output across runs
+	keys := f.orderedKeys(data, order)
 
-	// 2. Append each field
-	b.WriteByte(' ') // Lead with a space to separate from the message
-	for i, k := range keys {
-		v := data[k]
+	// 2. Render each field, nested values as several
+	var fields []field
+	for _, k := range keys {
+		fields = f.renderField(fields, k, data[k], 0)
+	}
+	if len(f.HiddenFields) > 0 {
+		fields = slices.DeleteFunc(fields, func(fd field) bool { return f.hidden(fd.key) })
+	}
 
+	p := f.palette()
+	tokens := make([]string, 0, len(fields))
+	for _, fd := range fields {
 		if f.UseColors {
-			// Key in Dim Gray, Value in a slightly brighter Gray
-			b.WriteString(fmt.Sprintf("%s%s=%s%v%s", ColorVeryDimGray, k, ColorGray, v, ColorReset))
+			// Key dimmer than the value, e.g. Dim Gray and Gray in the dark theme
+			tokens = append(tokens, p.fieldKey+fd.key+"="+p.value(fd.kind)+fd.value+ColorReset)
 		} else {
-			b.WriteString(fmt.Sprintf("%s=%v", k, v))
+			tokens = append(tokens, fd.key+"="+fd.value)
+		}
+	}
+	return tokens
+}
+
+// findTag returns the text of the first bracketed tag in message and its location
+func findTag(message string) (inner string, loc []int) {
+	loc = bracketRegex.FindStringIndex(message)
+	if loc == nil {
+		return "", nil
+	}
+	return strings.Trim(message[loc[0]:loc[1]], "[]"), loc
+}
+
+// findTagPath returns the first tag and the tags directly following it, e.g.
+// ["API", "Users"] for "[API][Users] created", and the location of the whole run
+func findTagPath(message string) (tags []string, loc []int) {
+	inner, loc := findTag(message)
+	if loc == nil {
+		return nil, nil
+	}
+	tags = []string{inner}
+	end := loc[1]
+	for {
+		m := nextTagRegex.FindStringSubmatchIndex(message[end:])
+		if m == nil {
+			break
+		}
+		tags = append(tags, message[end+m[2]:end+m[3]])
+		end += m[1]
+	}
+	return tags, []int{loc[0], end}
+}
+
+// findTags finds the tags of message according to the TagPath mode
+func (f *CustomFormatter) findTags(message string) (tags []string, loc []int) {
+	if f.TagPath == TagPathOff {
+		inner, loc := findTag(message)
+		if loc == nil {
+			return nil, nil
+		}
+		return []string{inner}, loc
+	}
+	return findTagPath(message)
+}
+
+// styledTag renders a single tag with the configured style and colors.
+// colorTag selects the color, so a joined path keeps the color of its first tag.
+func (f *CustomFormatter) styledTag(inner, colorTag string, maxPadding int) string {
+	if f.UseColors && f.ColorBrackets {
+		tagColor := f.tagColor(colorTag)
+		if f.TagStyle == StyleCenter || f.TagStyle == StyleRight {
+			return f.coloredTagWithPadding(inner, tagColor, maxPadding, f.TagStyle)
 		}
+		return tagColor + "[" + inner + "]" + ColorReset
+	}
+	switch f.TagStyle {
+	case StyleCenter:
+		return f.centerTag(inner, maxPadding)
+	case StyleRight:
+		return f.rightPadTag(inner, maxPadding)
+	default:
+		return "[" + inner + "]"
+	}
+}
+
+// writeTagColumns writes the tag gutter and returns its width. Every column is
+// padded to maxPadding, so messages line up whatever the tags are.
+func (f *CustomFormatter) writeTagColumns(b *strings.Builder, tags []string, maxPadding int) int {
+	columns := []string{""}
+	switch {
+	case len(tags) == 0:
+	case f.TagPath == TagPathColumns:
+		columns = tags
+	default:
+		sep := f.TagSeparator
+		if sep == "" {
+			sep = DefaultTagSeparator
+		}
+		columns = []string{strings.Join(tags, sep)}
+	}
 
-		// Add space between fields, but not after the last one
-		if i < len(keys)-1 {
+	count := len(columns)
+	if f.TagPath == TagPathColumns {
+		reserved := f.TagColumns
+		if reserved <= 0 {
+			reserved = 2
+		}
+		count = max(count, reserved)
+	}
+
+	width := 0
+	for i := 0; i < count; i++ {
+		if i > 0 {
 			b.WriteByte(' ')
+			width++
+		}
+		var displayTag string
+		if i < len(columns) && columns[i] != "" {
+			colorTag := columns[i]
+			if f.TagPath != TagPathColumns {
+				colorTag = tags[0]
+			}
+			displayTag = f.styledTag(columns[i], colorTag, maxPadding)
+			b.WriteString(displayTag)
+		}
+		// displayWidth ignores color codes and counts wide characters as two cells
+		visibleLen := displayWidth(displayTag)
+		if visibleLen < maxPadding {
+			b.WriteString(strings.Repeat(" ", maxPadding-visibleLen))
 		}
+		width += max(maxPadding, visibleLen)
 	}
+	return width
+}
+
+// writeNamespace writes the padded namespace column and returns its width
+func (f *CustomFormatter) writeNamespace(b *strings.Builder, ns string) int {
+	width := f.NamespacePadding
+	if width <= 0 {
+		width = 10
+	}
+	nsWidth := displayWidth(ns)
+	width = max(width, nsWidth)
+
+	if f.UseColors {
+		b.WriteString(f.palette().namespace + ns + ColorReset)
+	} else {
+		b.WriteString(ns)
+	}
+	b.WriteString(strings.Repeat(" ", width-nsWidth) + " ")
+	return width + 1
+}
+
+// forFile returns the formatter to use for files and other non-console
+// writers: without the settings that only make sense on a terminal
+func (f *CustomFormatter) forFile() *CustomFormatter {
+	if f.LineWidth == 0 && len(f.HiddenFields) == 0 {
+		return f
+	}
+	file := *f
+	file.LineWidth = 0      // Files keep whole lines
+	file.HiddenFields = nil // and every field
+	return &file
+}
+
+// withoutField returns a copy of data without key
+func withoutField(data logrus.Fields, key string) logrus.Fields {
+	fields := make(logrus.Fields, len(data))
+	for k, v := range data {
+		if k != key {
+			fields[k] = v
+		}
+	}
+	return fields
 }
 
 func stripANSI(str string) string {
-	ansi := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
-	return ansi.ReplaceAllString(str, "")
+	return ansiRegex.ReplaceAllString(str, "")
 }
 
 func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
@@ -393,66 +659,73 @@ func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	b.WriteString(colorCode + level)
 	b.WriteString(strings.Repeat(" ", max(0, 6-len(level))) + resetCode + " ")
 
-	// 2. Bracketed Tag Handling
+	// 2. Namespace column, never repeated among the fields
+	fields := entry.Data
+	nsWidth := 0
+	ns, inData := namespaceOf(entry)
+	if inData {
+		fields = withoutField(entry.Data, NamespaceKey)
+	}
+	if f.ShowNamespace && ns != "" {
+		nsWidth = f.writeNamespace(&b, ns)
+	}
+
+	// 3. Bracketed Tag Handling
 	maxPadding := f.BracketPadding
 	if maxPadding <= 0 {
 		maxPadding = 15
 	}
 
-	var displayTag string
-	if loc := bracketRegex.FindStringIndex(message); loc != nil {
-		inner := strings.Trim(message[loc[0]:loc[1]], "[]")
-
-		// 1. Generate the styled tag string
-		if f.UseColors && f.ColorBrackets {
-			if f.TagStyle == StyleCenter || f.TagStyle == StyleRight {
-				displayTag = f.coloredTagWithPadding(inner, maxPadding, f.TagStyle)
-			} else {
-				displayTag = ColorYellow + "[" + inner + "]" + ColorReset
-			}
-		} else {
-			switch f.TagStyle {
-			case StyleCenter:
-				displayTag = f.centerTag(inner, maxPadding)
-			case StyleRight:
-				displayTag = f.rightPadTag(inner, maxPadding)
-			default:
-				displayTag = "[" + inner + "]"
-			}
-		}
-
-		// 2. Write the tag and clean up the message
-		b.WriteString(displayTag)
+	// Write the tags padded to the gutter and clean up the message
+	tags, loc := f.findTags(message)
+	if loc != nil {
 		message = strings.TrimSpace(message[:loc[0]] + message[loc[1]:])
 	}
+	gutter := f.writeTagColumns(&b, tags, maxPadding)
+	b.WriteByte(' ') // Single space separator before the message text
 
-	// 3. Calculate remaining gutter space
-	// stripANSI ensures we don't count invisible color codes
-	visibleLen := len(stripANSI(displayTag))
-	if visibleLen < maxPadding {
-		b.WriteString(strings.Repeat(" ", maxPadding-visibleLen))
+	// Calculate how many spaces we need to skip to reach the message column
+	// Timestamp (approx 22) + Level (7) + Gutter (tag columns + 1)
+	prefixWidth := 0
+	if f.ShowTimestamp {
+		prefixWidth += 22 // "[2006-01-02 15:04:05] "
+	}
+	prefixWidth += 7       // "LEVEL  " (Level 6 + 1 space)
+	prefixWidth += nsWidth // The namespace column, if shown
+	prefixWidth += gutter  // The tag gutter
+	prefixWidth += 1       // The final separator space
+	indent := strings.Repeat(" ", prefixWidth)
+
+	// An error with a chain or stack is shown below the line instead of as a field
+	err := entryError(entry)
+	stack := errorStackFor(entry, err, f.ErrorStacks, f.CaptureStack, f.StackLevel)
+	tree := f.errorTree(err, stack)
+	if tree != nil {
+		fields = withoutField(fields, logrus.ErrorKey)
 	}
-	b.WriteByte(' ') // Single space separator before the message text
 
-	// 3. Message & Fields
-	b.WriteString(message)
-	if len(entry.Data) > 0 {
-		f.appendFields(&b, entry.Data)
+	// 4. Message & Fields
+	var tokens []string
+	if len(fields) > 0 {
+		tokens = f.fieldTokens(fields, fieldOrderOf(entry))
 	}
 
-	// 4. Caller Info
-	if f.ShowCaller && entry.Level <= f.CallerLevel {
-		// Calculate how many spaces we need to skip to reach the message column
-		// Timestamp (approx 22) + Level (7) + Gutter (maxPadding + 1)
-		prefixWidth := 0
-		if f.ShowTimestamp {
-			prefixWidth += 22 // "[2006-01-02 15:04:05] "
+	// 5. Caller Info
+	showCaller := f.ShowCaller && entry.Level <= f.CallerLevel
+	inlineCaller := ""
+	if showCaller && f.CallerPlacement == CallerInline {
+		if callerInfo := f.formatCallerInfo(entry); callerInfo != "" {
+			inlineCaller = "  " + callerInfo
 		}
-		prefixWidth += 7          // "LEVEL  " (Level 6 + 1 space)
-		prefixWidth += maxPadding // The tag gutter
-		prefixWidth += 1          // The final separator space
+	}
+	f.writeBody(&b, f.formatMultiline(message, indent), tokens, inlineCaller, prefixWidth, indent)
+
+	// 6. Error tree and stack trace
+	if tree != nil || len(stack) > 0 {
+		f.writeErrorTree(&b, tree, stack, indent)
+	}
 
-		indent := strings.Repeat(" ", prefixWidth)
+	if showCaller && f.CallerPlacement != CallerInline {
 		b.WriteString("\n" + indent + f.formatCallerInfo(entry))
 	}
 
@@ -2,6 +2,7 @@ package pretty
 
 import (
 	"fmt"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -1337,3 +1338,82 @@ func TestFormatter_TagStyle_AllStyles_Comparison(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatter_TagPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    TagPathMode
+		sep     string
+		want    string
+		message string
+	}{
+		{"Off", TagPathOff, "", "INFO   [API]           [Users] created\n", "[API][Users] created"},
+		{"Joined", TagPathJoined, "", "INFO   [API›Users]     created\n", "[API][Users] created"},
+		{"JoinedSeparator", TagPathJoined, "/", "INFO   [API/Users]     created\n", "[API] [Users] created"},
+		{"Columns", TagPathColumns, "", "INFO   [API]           [Users]         created\n", "[API][Users] created"},
+		{"ColumnsSingleTag", TagPathColumns, "", "INFO   [API]                           created\n", "[API] created"},
+		{"ColumnsUntagged", TagPathColumns, "", "INFO                                   created\n", "created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &CustomFormatter{BracketPadding: 15, TagPath: tt.mode, TagSeparator: tt.sep}
+			entry := logrus.NewEntry(logrus.New())
+			entry.Message = tt.message
+			entry.Level = logrus.InfoLevel
+
+			b, err := f.Format(entry)
+			if err != nil {
+				t.Fatalf("Format error: %v", err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, string(b))
+			}
+		})
+	}
+}
+
+func TestFormatter_TagPath_CallerAlignment(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithNamespaceColumn(false, 0), WithTagPath(TagPathColumns, ""))
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "[API][Users] failed"
+	entry.Level = logrus.ErrorLevel
+	entry.Caller = &runtime.Frame{File: "main.go", Line: 7}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+	lines := strings.Split(string(b), "\n")
+	msgCol := strings.Index(lines[0], "failed")
+	callerCol := strings.Index(lines[1], "└─")
+	if msgCol != callerCol {
+		t.Errorf("Expected caller under message column %d, got %d:\n%s", msgCol, callerCol, b)
+	}
+}
+
+func TestFindTagPath(t *testing.T) {
+	tags, loc := findTagPath("[API] [Users][Create] done [Later]")
+	if strings.Join(tags, ",") != "API,Users,Create" {
+		t.Errorf("Expected leading tags only, got %v", tags)
+	}
+	if loc[0] != 0 || loc[1] != len("[API] [Users][Create]") {
+		t.Errorf("Unexpected location %v", loc)
+	}
+	if tags, loc := findTagPath("no tags"); tags != nil || loc != nil {
+		t.Errorf("Expected no tags, got %v %v", tags, loc)
+	}
+}
+
+// formatEntry formats an entry with the given level, message and fields
+func formatEntry(t *testing.T, f *CustomFormatter, level logrus.Level, message string, fields logrus.Fields) string {
+	t.Helper()
+	entry := logrus.NewEntry(logrus.New()).WithFields(fields)
+	entry.Message = message
+	entry.Level = level
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+	return string(b)
+}
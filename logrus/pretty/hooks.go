@@ -1,9 +1,11 @@
 package pretty
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -12,33 +14,76 @@ type MultiWriterWithFormattersConfig struct {
 	format       FormatType
 	showCaller   bool
 	customFormat *CustomFormatter
+	theme        *Theme
+	async        *AsyncConfig // Queue entries per writer, see WithAsync
 }
 type writerPair struct {
-	w io.Writer
-	f logrus.Formatter
+	w    io.Writer
+	f    logrus.Formatter
+	sink *Sink       // Levels and filter of pairs added with AddSink
+	q    *asyncQueue // Set in async mode
 }
 
 type MultiWriter struct {
-	pairs []writerPair
-	cfg   MultiWriterWithFormattersConfig
+	pairs  []writerPair
+	cfg    MultiWriterWithFormattersConfig
+	filter *tagFilter // Optional per-tag level filter, applied before any formatter, see Sink.allows
 }
 
 func NewMultiWriter(cfg MultiWriterWithFormattersConfig) *MultiWriter {
 	return &MultiWriter{cfg: cfg}
 }
 
+// AddWriter adds a target with its own formatter.
+//
+// useColors asks for colors on w; they are only used if w supports them, see
+// DetectColors. FORCE_COLOR turns them on for writers that are not terminals.
 func (mw *MultiWriter) AddWriter(w io.Writer, useColors, showTime bool) {
+	mw.addPair(w, mw.formatterFor(w, useColors, showTime), nil)
+}
+
+// addPair adds w with formatter f, starting its queue in async mode
+func (mw *MultiWriter) addPair(w io.Writer, f logrus.Formatter, s *Sink) {
+	p := writerPair{w: w, f: f, sink: s}
+	if mw.cfg.async != nil {
+		var dropped *atomic.Uint64
+		if s != nil {
+			dropped = &s.dropped
+		}
+		p.q = newAsyncQueue(w, *mw.cfg.async, dropped)
+	}
+	mw.pairs = append(mw.pairs, p)
+}
+
+// formatterFor builds the formatter for w from the MultiWriter's format
+func (mw *MultiWriter) formatterFor(w io.Writer, useColors, showTime bool) logrus.Formatter {
 	var f logrus.Formatter
 
+	detected, depth := DetectColors(w)
+	useColors = useColors && detected
+
 	if mw.cfg.customFormat != nil {
 		custom := *mw.cfg.customFormat
+		if !isConsole(w) {
+			custom = *custom.forFile()
+		}
 		custom.UseColors = useColors
 		custom.ShowTimestamp = showTime
+		if custom.Theme == nil {
+			custom.Theme = mw.cfg.theme
+		}
+		if custom.ColorDepth == 0 {
+			custom.ColorDepth = depth
+		}
 		f = &custom
 	} else {
 		switch mw.cfg.format {
 		case FormatJSON:
 			f = &logrus.JSONFormatter{}
+		case FormatJSONTagged:
+			jf := NewJSONFormatter()
+			jf.ShowCaller = mw.cfg.showCaller
+			f = jf
 		case FormatPlain:
 			f = &CustomFormatter{
 				UseColors:       useColors,
@@ -48,26 +93,54 @@ func (mw *MultiWriter) AddWriter(w io.Writer, useColors, showTime bool) {
 				UseRelativePath: true,
 				BracketPadding:  15,
 				ColorBrackets:   true,
+				ShowNamespace:   true,
+				Theme:           mw.cfg.theme,
+				ColorDepth:      depth,
+				ErrorTree:       true,
+				ErrorStacks:     true,
+				StackLevel:      logrus.ErrorLevel,
+				Multiline:       MultilineIndent,
 			}
 		default:
-			f = &logrus.TextFormatter{ForceColors: useColors}
+			f = &logrus.TextFormatter{ForceColors: useColors, DisableColors: !useColors}
 		}
 	}
+	return f
+}
 
-	mw.pairs = append(mw.pairs, writerPair{w, f})
+// allows reports whether p writes e; outputs that are not sinks follow filter
+func (p writerPair) allows(e *logrus.Entry, filter *tagFilter) bool {
+	if p.sink != nil {
+		return p.sink.allows(e, filter)
+	}
+	return filter == nil || filter.allows(e)
 }
 
 func (mw *MultiWriter) WriteEntry(e *logrus.Entry) error {
 	for _, p := range mw.pairs {
+		if !p.allows(e, mw.filter) {
+			continue
+		}
 		buf, err := p.f.Format(e)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "log format err: %v\n", err)
 			continue
 		}
+		if p.q != nil {
+			p.q.enqueue(buf, e.Level)
+			continue
+		}
 		if _, err := p.w.Write(buf); err != nil {
 			fmt.Fprintf(os.Stderr, "log write err: %v\n", err)
 		}
 	}
+	if mw.cfg.async != nil && e.Level <= logrus.FatalLevel {
+		// The program exits or panics next, so write everything queued now,
+		// waiting no longer than a Fatal exit would
+		ctx, cancel := context.WithTimeout(context.Background(), ExitTimeout)
+		defer cancel()
+		return mw.flush(ctx)
+	}
 	return nil
 }
 
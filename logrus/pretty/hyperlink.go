@@ -0,0 +1,139 @@
+package pretty
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// URL templates for CustomFormatter.CallerLink. Placeholders:
+//
+//	{abspath}  absolute file path
+//	{relpath}  path relative to the module's go.mod, for repository links
+//	{line}     line number
+//	{commit}   CallerLinkCommit, or the VCS revision the binary was built from
+const (
+	CallerLinkFile   = "file://{abspath}"
+	CallerLinkVSCode = "vscode://file{abspath}:{line}"
+	CallerLinkIDEA   = "idea://open?file={abspath}&line={line}"
+)
+
+// callerLinkNames are the presets accepted by name in config files
+var callerLinkNames = map[string]string{
+	"file":   CallerLinkFile,
+	"vscode": CallerLinkVSCode,
+	"idea":   CallerLinkIDEA,
+}
+
+// CallerLinkRepo returns a template linking into a repository browser such as
+// GitHub or GitLab, e.g. CallerLinkRepo("https://github.com/acme/app") gives
+// "https://github.com/acme/app/blob/{commit}/{relpath}#L{line}".
+func CallerLinkRepo(baseURL string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/blob/{commit}/{relpath}#L{line}"
+}
+
+// WithCallerLink makes the caller a clickable OSC 8 hyperlink built from the
+// URL template. Links are only written with colors on, so files and piped
+// output stay plain, and on terminals DetectHyperlinks knows to support them.
+func WithCallerLink(template string) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.CallerLink = template
+	}
+}
+
+// WithCallerLinkAlways writes the caller link on any terminal with colors,
+// for terminals DetectHyperlinks doesn't recognize
+func WithCallerLinkAlways(enabled bool) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.CallerLinkAlways = enabled
+	}
+}
+
+// DetectHyperlinks reports whether the terminal shows OSC 8 hyperlinks, so
+// other terminals don't print the escape codes:
+//
+//   - FORCE_HYPERLINK enables them ("0" or "false" disables)
+//   - tmux and screen disable them, since they may not pass them through
+//   - iTerm2, WezTerm, VS Code, Ghostty, Windows Terminal, Konsole, kitty and
+//     VTE terminals (GNOME Terminal, Tilix) 0.50 and later enable them
+//
+// Other terminals get no links.
+func DetectHyperlinks() bool {
+	if force, ok := os.LookupEnv("FORCE_HYPERLINK"); ok {
+		switch strings.ToLower(strings.TrimSpace(force)) {
+		case "0", "false":
+			return false
+		default:
+			return true
+		}
+	}
+	term := os.Getenv("TERM")
+	if os.Getenv("TMUX") != "" || os.Getenv("STY") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux") {
+		return false
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty":
+		return true
+	}
+	if vte, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+	return os.Getenv("WT_SESSION") != "" || os.Getenv("KONSOLE_VERSION") != "" || term == "xterm-kitty"
+}
+
+// hyperlink wraps text in an OSC 8 hyperlink. Terminals without support show the text only.
+func hyperlink(target, text string) string {
+	return "\033]8;;" + target + "\033\\" + text + "\033]8;;\033\\"
+}
+
+// callerURL fills the CallerLink template for frame
+func (f *CustomFormatter) callerURL(frame *runtime.Frame) string {
+	abs := frame.File
+	if a, err := filepath.Abs(abs); err == nil {
+		abs = a
+	}
+	rel := filepath.Base(abs)
+	if root, ok := moduleRoot(filepath.Dir(abs)); ok {
+		if r, err := filepath.Rel(root, abs); err == nil {
+			rel = r
+		}
+	}
+
+	commit := f.CallerLinkCommit
+	if commit == "" {
+		commit = buildRevision()
+	}
+
+	return strings.NewReplacer(
+		"{abspath}", escapePath(abs),
+		"{relpath}", escapePath(rel),
+		"{line}", strconv.Itoa(frame.Line),
+		"{commit}", url.PathEscape(commit),
+	).Replace(f.CallerLink)
+}
+
+// escapePath percent-encodes a file path for use in a URL, keeping the slashes
+func escapePath(p string) string {
+	p = filepath.ToSlash(p)
+	if !strings.HasPrefix(p, "/") && filepath.VolumeName(p) != "" {
+		p = "/" + p // Windows drive letters: C:/x -> /C:/x
+	}
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+// buildRevision returns the VCS revision stamped into the binary, or "HEAD"
+var buildRevision = sync.OnceValue(func() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" && s.Value != "" {
+				return s.Value
+			}
+		}
+	}
+	return "HEAD"
+})
@@ -0,0 +1,119 @@
+package pretty
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCallerURL(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(root, "auth", "my login.go")
+	frame := &runtime.Frame{File: file, Line: 42}
+	abs := filepath.ToSlash(file)
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"File", CallerLinkFile, "file://" + strings.ReplaceAll(abs, " ", "%20")},
+		{"VSCode", CallerLinkVSCode, "vscode://file" + strings.ReplaceAll(abs, " ", "%20") + ":42"},
+		{"IDEA", CallerLinkIDEA, "idea://open?file=" + strings.ReplaceAll(abs, " ", "%20") + "&line=42"},
+		{"Repo", CallerLinkRepo("https://github.com/acme/app/"), "https://github.com/acme/app/blob/abc123/auth/my%20login.go#L42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewCustomFormatter(WithCallerLink(tt.template))
+			f.CallerLinkCommit = "abc123"
+			if got := f.callerURL(frame); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// clearHyperlinkEnv unsets every variable DetectHyperlinks reads
+func clearHyperlinkEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{"FORCE_HYPERLINK", "TERM", "TMUX", "STY", "TERM_PROGRAM", "VTE_VERSION", "WT_SESSION", "KONSOLE_VERSION"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+}
+
+func TestDetectHyperlinks(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{"Unknown", map[string]string{"TERM": "xterm-256color"}, false},
+		{"ITerm", map[string]string{"TERM_PROGRAM": "iTerm.app"}, true},
+		{"VSCode", map[string]string{"TERM_PROGRAM": "vscode"}, true},
+		{"VTE", map[string]string{"VTE_VERSION": "6003"}, true},
+		{"OldVTE", map[string]string{"VTE_VERSION": "4205"}, false},
+		{"WindowsTerminal", map[string]string{"WT_SESSION": "1"}, true},
+		{"Kitty", map[string]string{"TERM": "xterm-kitty"}, true},
+		{"Tmux", map[string]string{"TERM_PROGRAM": "iTerm.app", "TMUX": "/tmp/tmux-0/default,1,0"}, false},
+		{"Screen", map[string]string{"TERM": "screen-256color", "VTE_VERSION": "6003"}, false},
+		{"Force", map[string]string{"FORCE_HYPERLINK": "1", "TMUX": "/tmp/tmux"}, true},
+		{"ForceOff", map[string]string{"FORCE_HYPERLINK": "0", "TERM_PROGRAM": "vscode"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearHyperlinkEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if got := DetectHyperlinks(); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatter_CallerHyperlink(t *testing.T) {
+	clearHyperlinkEnv(t)
+	t.Setenv("TERM_PROGRAM", "vscode")
+	frame := &runtime.Frame{File: "/src/app/main.go", Line: 3}
+
+	f := NewCustomFormatter(WithCallerLink(CallerLinkFile))
+	got := f.formatCallerInfo(callerEntry(frame))
+	if !strings.HasPrefix(got, "\033]8;;file:///src/app/main.go\033\\") || !strings.HasSuffix(got, "\033]8;;\033\\") {
+		t.Errorf("Expected OSC 8 link around the caller, got %q", got)
+	}
+	if stripANSI(got) != stripANSI(f.formatCallerInfo(callerEntry(frame))) || strings.Contains(stripANSI(got), "\033") {
+		t.Errorf("Expected stripANSI to remove the link, got %q", stripANSI(got))
+	}
+
+	t.Setenv("TERM_PROGRAM", "Apple_Terminal")
+	if got := f.formatCallerInfo(callerEntry(frame)); strings.Contains(got, "\033]8") {
+		t.Errorf("Expected no link on a terminal without support, got %q", got)
+	}
+	f.CallerLinkAlways = true
+	if got := f.formatCallerInfo(callerEntry(frame)); !strings.Contains(got, "\033]8") {
+		t.Errorf("Expected CallerLinkAlways to write the link, got %q", got)
+	}
+
+	f.UseColors = false
+	if got := f.formatCallerInfo(callerEntry(frame)); strings.Contains(got, "\033]8") {
+		t.Errorf("Expected no link without colors, got %q", got)
+	}
+}
+
+func TestFormatter_CallerHyperlinkKeepsAlignment(t *testing.T) {
+	f := NewCustomFormatter(WithCallerLink(CallerLinkVSCode), WithCallerFormat(CallerFormatShort, CallerInline))
+	b, err := f.Format(callerEntry(&runtime.Frame{File: "/src/app/main.go", Line: 3, Function: "main.run"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stripANSI(string(b)); !strings.HasSuffix(got, "failed  main.run (main.go:3)\n") {
+		t.Errorf("Unexpected visible output %q", got)
+	}
+}
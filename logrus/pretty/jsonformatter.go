@@ -0,0 +1,151 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Keys written by JSONFormatter. User fields with the same name are kept
+// under "fields.<key>" instead of being overwritten.
+const (
+	JSONKeyTime      = "time"
+	JSONKeyLevel     = "level"
+	JSONKeyMessage   = "msg"
+	JSONKeyTag       = "tag"
+	JSONKeyTags      = "tags"
+	JSONKeyNamespace = NamespaceKey
+	JSONKeyCaller    = "caller"
+	JSONKeyFunc      = "func"
+	JSONKeyStack     = "stack"
+)
+
+// JSONFormatter writes one JSON object per entry with the bracket tag as its
+// own field, so log pipelines can index it:
+//
+//	{"level":"warn","msg":"Login failed","namespace":"Main","tag":"Auth","time":"..."}
+//
+// The tag is found the same way CustomFormatter finds it and removed from msg.
+// With TagPath, "[API][Users] created" yields "tag":"API","tags":["API","Users"].
+type JSONFormatter struct {
+	ExtractTag      bool   // Move the bracket tag from msg into "tag"
+	TagPath         bool   // With ExtractTag, also move tags following the first one and list all in "tags"
+	ShowNamespace   bool   // Keep the "namespace" field set by New and Child
+	ShowCaller      bool   // Add "caller" (file:line) and "func" when the entry has a caller
+	UseRelativePath bool   // Caller path relative to the working directory
+	NormalizeLevel  bool   // Short lower-case level names: "warn" instead of "warning"
+	TimestampFormat string // Defaults to time.RFC3339
+	PrettyPrint     bool   // Indent the JSON output
+
+	// StructuredErrors writes the error field as a JSONError object with its
+	// kind, message, unwrapped chain and carried stack instead of a string
+	StructuredErrors bool
+	// CaptureStack adds the stack of the logging goroutine to entries at
+	// StackLevel and above whose error carries none. Without an error field the
+	// stack is written under "stack".
+	CaptureStack bool
+	StackLevel   logrus.Level
+}
+
+// NewJSONFormatter returns a JSONFormatter with tag extraction, namespace,
+// caller, normalized levels and structured errors enabled
+func NewJSONFormatter() *JSONFormatter {
+	return &JSONFormatter{
+		ExtractTag:      true,
+		TagPath:         true,
+		ShowNamespace:   true,
+		ShowCaller:      true,
+		UseRelativePath: true,
+		NormalizeLevel:  true,
+
+		StructuredErrors: true,
+		StackLevel:       logrus.ErrorLevel,
+	}
+}
+
+// Format implements logrus.Formatter
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	ns, nsInData := namespaceOf(entry)
+	data := make(logrus.Fields, len(entry.Data)+6)
+	for k, v := range entry.Data {
+		if k == NamespaceKey && nsInData || k == FieldOrderKey {
+			continue
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error() // encoding/json would drop the message of most errors
+		}
+		data[k] = v
+	}
+
+	set := func(key string, v any) {
+		if old, ok := data[key]; ok {
+			data["fields."+key] = old
+		}
+		data[key] = v
+	}
+
+	err := entryError(entry)
+	stack := errorStackFor(entry, err, f.StructuredErrors, f.CaptureStack, f.StackLevel)
+	switch {
+	case err != nil && f.StructuredErrors:
+		data[logrus.ErrorKey] = newJSONError(err, stack, f.UseRelativePath)
+	case len(stack) > 0:
+		set(JSONKeyStack, jsonStack(stack, f.UseRelativePath))
+	}
+
+	message := entry.Message
+	if f.ExtractTag {
+		if f.TagPath {
+			if tags, loc := findTagPath(message); loc != nil {
+				message = strings.TrimSpace(message[:loc[0]] + message[loc[1]:])
+				set(JSONKeyTag, tags[0])
+				set(JSONKeyTags, tags)
+			}
+		} else if inner, loc := findTag(message); loc != nil {
+			message = strings.TrimSpace(message[:loc[0]] + message[loc[1]:])
+			set(JSONKeyTag, inner)
+		}
+	}
+	if f.ShowNamespace && ns != "" {
+		set(JSONKeyNamespace, ns)
+	}
+	if f.ShowCaller && entry.Caller != nil {
+		set(JSONKeyCaller, fmt.Sprintf("%s:%d", callerPath(entry.Caller.File, f.UseRelativePath), entry.Caller.Line))
+		set(JSONKeyFunc, entry.Caller.Function)
+	}
+
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = time.RFC3339
+	}
+	if !entry.Time.IsZero() {
+		set(JSONKeyTime, entry.Time.Format(timestampFormat))
+	}
+	set(JSONKeyLevel, f.levelName(entry.Level))
+	set(JSONKeyMessage, message)
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if f.PrettyPrint {
+		enc.SetIndent("", "  ")
+	}
+	if err := enc.Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal fields to JSON: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+func (f *JSONFormatter) levelName(level logrus.Level) string {
+	if !f.NormalizeLevel {
+		return level.String()
+	}
+	if level == logrus.WarnLevel {
+		return "warn"
+	}
+	return level.String()
+}
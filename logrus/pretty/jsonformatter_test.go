@@ -0,0 +1,155 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func formatJSON(t *testing.T, f *JSONFormatter, entry *logrus.Entry) map[string]any {
+	t.Helper()
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var out map[string]any
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", b, err)
+	}
+	return out
+}
+
+func TestJSONFormatter_ExtractsTag(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithField(NamespaceKey, "Main")
+	entry.Message = "[Auth] Login failed"
+	entry.Level = logrus.WarnLevel
+	entry.Time = time.Now()
+
+	out := formatJSON(t, NewJSONFormatter(), entry)
+
+	if out["tag"] != "Auth" {
+		t.Errorf("Expected tag Auth, got %v", out["tag"])
+	}
+	if out["msg"] != "Login failed" {
+		t.Errorf("Expected tag stripped from msg, got %q", out["msg"])
+	}
+	if out["namespace"] != "Main" {
+		t.Errorf("Expected namespace Main, got %v", out["namespace"])
+	}
+	if out["level"] != "warn" {
+		t.Errorf("Expected normalized level warn, got %v", out["level"])
+	}
+}
+
+func TestJSONFormatter_Options(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithField(NamespaceKey, "Main")
+	entry.Message = "[Auth] Login failed"
+	entry.Level = logrus.WarnLevel
+
+	out := formatJSON(t, &JSONFormatter{}, entry)
+
+	if _, ok := out["tag"]; ok {
+		t.Error("Expected no tag field without ExtractTag")
+	}
+	if out["msg"] != "[Auth] Login failed" {
+		t.Errorf("Expected untouched msg, got %q", out["msg"])
+	}
+	if _, ok := out["namespace"]; ok {
+		t.Error("Expected namespace to be dropped without ShowNamespace")
+	}
+	if out["level"] != "warning" {
+		t.Errorf("Expected logrus level name, got %v", out["level"])
+	}
+}
+
+func TestJSONFormatter_UntaggedMessage(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "plain message"
+
+	out := formatJSON(t, NewJSONFormatter(), entry)
+
+	if _, ok := out["tag"]; ok {
+		t.Error("Expected no tag field for untagged message")
+	}
+	if out["msg"] != "plain message" {
+		t.Errorf("Expected msg unchanged, got %q", out["msg"])
+	}
+}
+
+func TestJSONFormatter_Caller(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "[DB] slow"
+	entry.Caller = &runtime.Frame{File: "/src/app/db.go", Line: 42, Function: "app.Query"}
+
+	out := formatJSON(t, &JSONFormatter{ShowCaller: true}, entry)
+
+	if out["caller"] != "/src/app/db.go:42" {
+		t.Errorf("Expected caller file:line, got %v", out["caller"])
+	}
+	if out["func"] != "app.Query" {
+		t.Errorf("Expected func, got %v", out["func"])
+	}
+}
+
+func TestJSONFormatter_FieldClashAndErrors(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New()).WithFields(logrus.Fields{
+		"tag":   "user-tag",
+		"error": errors.New("boom"),
+	})
+	entry.Message = "[Auth] failed"
+
+	f := NewJSONFormatter()
+	f.StructuredErrors = false
+	out := formatJSON(t, f, entry)
+
+	if out["tag"] != "Auth" || out["fields.tag"] != "user-tag" {
+		t.Errorf("Expected clashing field kept as fields.tag, got tag=%v fields.tag=%v", out["tag"], out["fields.tag"])
+	}
+	if out["error"] != "boom" {
+		t.Errorf("Expected error rendered as its message, got %v", out["error"])
+	}
+}
+
+func TestNew_JSONTaggedFormat(t *testing.T) {
+	logger := New(WithFormat(FormatJSONTagged), WithoutCaller())
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	logger.Info("[Cache] warmed")
+
+	var out map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", buf.String(), err)
+	}
+	if out["tag"] != "Cache" || out["msg"] != "warmed" || out["namespace"] != "Main" {
+		t.Errorf("Unexpected output: %v", out)
+	}
+	if _, ok := out["caller"]; ok {
+		t.Error("Expected no caller with WithoutCaller")
+	}
+}
+
+func TestJSONFormatter_TagPath(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+	entry.Message = "[API][Users] created"
+
+	out := formatJSON(t, NewJSONFormatter(), entry)
+
+	tags, _ := out["tags"].([]any)
+	if len(tags) != 2 || tags[0] != "API" || tags[1] != "Users" {
+		t.Errorf("Expected tags [API Users], got %v", out["tags"])
+	}
+	if out["tag"] != "API" || out["msg"] != "created" {
+		t.Errorf("Expected tag API and msg created, got tag=%v msg=%q", out["tag"], out["msg"])
+	}
+
+	out = formatJSON(t, &JSONFormatter{ExtractTag: true}, entry)
+	if _, ok := out["tags"]; ok || out["msg"] != "[Users] created" {
+		t.Errorf("Expected only the first tag extracted without TagPath, got %v", out)
+	}
+}
@@ -0,0 +1,90 @@
+package pretty
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// SetLevel sets the level of a logger created by New or Child. Unlike
+// l.SetLevel, it keeps tag levels in effect: untagged entries are held to
+// level, while the logger still lets through what tags and sinks ask for.
+//
+//	pretty.SetLevel(log, logrus.DebugLevel)
+//
+// The logrus level of l is then only a gate, the most verbose of level and the
+// tag and sink levels, so l.GetLevel and l.IsLevelEnabled may report more than
+// level. Use GetLevel and IsLevelEnabled from this package instead, and AddHook
+// for hooks that should only see the entries level lets through.
+//
+// On other loggers it is the same as l.SetLevel.
+func SetLevel(l *logrus.Logger, level logrus.Level) {
+	h := hookOf(l)
+	if h == nil {
+		l.SetLevel(level)
+		return
+	}
+	h.level.Store(uint32(level))
+	h.updateGate(l)
+}
+
+// GetLevel returns the level set with WithLevel or SetLevel, which is the
+// level of untagged entries. l.GetLevel may be more verbose when tag levels
+// need it.
+func GetLevel(l *logrus.Logger) logrus.Level {
+	if h := hookOf(l); h != nil {
+		return h.visible(l)
+	}
+	return l.GetLevel()
+}
+
+// IsLevelEnabled reports whether untagged entries at level are logged by l,
+// following GetLevel rather than the gate l.IsLevelEnabled checks
+func IsLevelEnabled(l *logrus.Logger, level logrus.Level) bool {
+	return GetLevel(l) >= level
+}
+
+// AddHook adds hook to l. On a logger created by New or Child the hook only
+// fires for entries its level and tag levels allow; a hook added with l.AddHook
+// also sees the entries the gate lets through for other tags and sinks, which
+// the outputs then drop. Children created later inherit the hook.
+func AddHook(l *logrus.Logger, hook logrus.Hook) {
+	l.AddHook(&levelHook{hook})
+}
+
+// levelHook skips the entries loggerHook marked held
+type levelHook struct {
+	logrus.Hook
+}
+
+func (h *levelHook) Fire(e *logrus.Entry) error {
+	if m := metaOf(e); m != nil && m.held {
+		return nil
+	}
+	return h.Hook.Fire(e)
+}
+
+// hookOf returns the logger hook installed by New or Child, if any
+func hookOf(l *logrus.Logger) *loggerHook {
+	for _, h := range l.Hooks[logrus.InfoLevel] {
+		if lh, ok := h.(*loggerHook); ok {
+			return lh
+		}
+	}
+	return nil
+}
+
+// updateGate sets the logrus level of l to the most verbose of its own level
+// and the levels its tags and sinks need
+func (h *loggerHook) updateGate(l *logrus.Logger) {
+	gate := max(logrus.Level(h.level.Load()), logrus.Level(h.floor.Load()))
+	h.gate.Store(uint32(gate))
+	l.SetLevel(gate)
+}
+
+// visible returns the level of l as the user sees it. If l.SetLevel was
+// called directly, that level wins.
+func (h *loggerHook) visible(l *logrus.Logger) logrus.Level {
+	if gate := l.GetLevel(); gate != logrus.Level(h.gate.Load()) {
+		return gate
+	}
+	return logrus.Level(h.level.Load())
+}
@@ -0,0 +1,74 @@
+package pretty
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetLevel_TagLevels(t *testing.T) {
+	logger := New(WithLevel(logrus.InfoLevel), WithTagLevels(map[string]logrus.Level{"DB": logrus.TraceLevel, "*": logrus.WarnLevel}))
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	if GetLevel(logger) != logrus.WarnLevel {
+		t.Errorf("Expected the \"*\" level, got %v", GetLevel(logger))
+	}
+
+	SetLevel(logger, logrus.DebugLevel)
+	logger.Debug("untagged")
+	logger.Trace("[DB] row")
+	logger.Trace("[API] hidden")
+	if out := buf.String(); !strings.Contains(out, "untagged") || !strings.Contains(out, "row") || strings.Contains(out, "hidden") {
+		t.Errorf("Expected untagged debug and DB trace lines only, got: %s", out)
+	}
+
+	child := Child(logger, "Sub")
+	if GetLevel(child) != logrus.DebugLevel || child.GetLevel() != logrus.TraceLevel {
+		t.Errorf("Expected the child to inherit level and gate, got %v and %v", GetLevel(child), child.GetLevel())
+	}
+}
+
+func TestGetLevel_SetDirectly(t *testing.T) {
+	logger := New(WithLevel(logrus.InfoLevel))
+	logger.SetLevel(logrus.ErrorLevel)
+	if GetLevel(logger) != logrus.ErrorLevel {
+		t.Errorf("Expected l.SetLevel to be reported, got %v", GetLevel(logger))
+	}
+
+	plain := logrus.New()
+	SetLevel(plain, logrus.DebugLevel)
+	if GetLevel(plain) != logrus.DebugLevel || plain.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected plain loggers to use their own level, got %v", plain.GetLevel())
+	}
+}
+
+type recordHook struct{ messages []string }
+
+func (h *recordHook) Levels() []logrus.Level { return logrus.AllLevels }
+func (h *recordHook) Fire(e *logrus.Entry) error {
+	h.messages = append(h.messages, e.Message)
+	return nil
+}
+
+func TestAddHook_SkipsDroppedEntries(t *testing.T) {
+	logger := New(WithLevel(logrus.InfoLevel), WithTagLevels(map[string]logrus.Level{"DB": logrus.TraceLevel}))
+	logger.SetOutput(&bytes.Buffer{})
+	hook := &recordHook{}
+	AddHook(logger, hook)
+
+	if !logger.IsLevelEnabled(logrus.TraceLevel) || IsLevelEnabled(logger, logrus.DebugLevel) || !IsLevelEnabled(logger, logrus.InfoLevel) {
+		t.Errorf("Expected the gate at trace and the configured level at info")
+	}
+
+	logger.Debug("untagged")
+	logger.Trace("[DB] row")
+	logger.Debug("[API] hidden")
+	logger.Info("shown")
+	Child(logger, "Sub").Debug("child")
+	if got := strings.Join(hook.messages, ","); got != "[DB] row,shown" {
+		t.Errorf("Expected the hook to see logged entries only, got %q", got)
+	}
+}
@@ -0,0 +1,149 @@
+package pretty
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LevelStatus is the JSON document served by LevelHandler
+type LevelStatus struct {
+	Level      string            `json:"level"`
+	Namespace  string            `json:"namespace,omitempty"`
+	Namespaces map[string]string `json:"namespaces,omitempty"` // Level of every namespace derived from this logger
+	Config     *EffectiveConfig  `json:"config,omitempty"`
+}
+
+// EffectiveConfig is the resolved configuration of a logger created by New
+type EffectiveConfig struct {
+	Output     string `json:"output"`
+	Format     string `json:"format"`
+	Filename   string `json:"filename,omitempty"`
+	ShowCaller bool   `json:"caller"`
+	ConfigFile string `json:"config_file,omitempty"`
+}
+
+// LevelRequest is the body accepted by LevelHandler on PUT and POST.
+// The same keys are also accepted as form or query values.
+type LevelRequest struct {
+	Level     string `json:"level"`
+	Namespace string `json:"namespace,omitempty"` // Empty targets the logger itself
+}
+
+// maxLevelRequestSize bounds the body of a change request
+const maxLevelRequestSize = 64 << 10
+
+// LevelHandler serves the level of a logger over HTTP.
+//
+//	GET         returns a LevelStatus with the level and effective config
+//	PUT / POST  changes the level, e.g. {"level": "debug"} or ?level=debug
+//
+// Adding "namespace" to a change request targets a child logger instead, such
+// as "Main/Auth". Every change is reported through the affected logger.
+func LevelHandler(l *logrus.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			writeLevelStatus(w, l)
+		case http.MethodPut, http.MethodPost:
+			changeLevel(w, r, l)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func changeLevel(w http.ResponseWriter, r *http.Request, l *logrus.Logger) {
+	req, err := decodeLevelRequest(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	level, err := logrus.ParseLevel(req.Level)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unknown level %q", req.Level), http.StatusBadRequest)
+		return
+	}
+
+	target := l
+	if req.Namespace != "" {
+		var ok bool
+		if target, ok = family(l)[req.Namespace]; !ok {
+			http.Error(w, fmt.Sprintf("unknown namespace %q", req.Namespace), http.StatusNotFound)
+			return
+		}
+	}
+
+	previous := GetLevel(target)
+	SetLevel(target, level)
+	reportLevelChange(target, previous, level, r)
+
+	writeLevelStatus(w, l)
+}
+
+func decodeLevelRequest(w http.ResponseWriter, r *http.Request) (LevelRequest, error) {
+	var req LevelRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxLevelRequestSize)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid JSON body: %w", err)
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return req, fmt.Errorf("invalid form body: %w", err)
+		}
+		req.Level = r.Form.Get("level")
+		req.Namespace = r.Form.Get("namespace")
+	}
+
+	if strings.TrimSpace(req.Level) == "" {
+		return req, fmt.Errorf("missing level")
+	}
+	return req, nil
+}
+
+// reportLevelChange logs the change through the logger itself, at a level that
+// is still enabled after the change
+func reportLevelChange(l *logrus.Logger, from, to logrus.Level, r *http.Request) {
+	reportAt := max(logrus.ErrorLevel, min(logrus.InfoLevel, to))
+	l.WithFields(logrus.Fields{
+		"from":   from.String(),
+		"to":     to.String(),
+		"remote": r.RemoteAddr,
+	}).Log(reportAt, "[Logger] Level changed via admin handler")
+}
+
+func writeLevelStatus(w http.ResponseWriter, l *logrus.Logger) {
+	status := LevelStatus{Level: GetLevel(l).String()}
+
+	if info, ok := lookup(l); ok {
+		cfg := info.cfg // As resolved by New or the last reload
+		status.Namespace = cfg.Namespace
+		status.Config = &EffectiveConfig{
+			Output:     cfg.getOutput().String(),
+			Format:     cfg.getFormat().String(),
+			Filename:   cfg.Filename,
+			ShowCaller: cfg.reportCaller(),
+			ConfigFile: cfg.ConfigFile,
+		}
+
+		members := family(l)
+		status.Namespaces = make(map[string]string, len(members))
+		for name, member := range members {
+			status.Namespaces[name] = GetLevel(member).String()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,224 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newHandlerLogger(t *testing.T) (*logrus.Logger, *bytes.Buffer) {
+	t.Helper()
+	t.Setenv("LOG_LEVEL", "")
+
+	logger := New(WithNamespace("Admin"))
+	t.Cleanup(func() { unregister(logger) })
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	return logger, &buf
+}
+
+func decodeStatus(t *testing.T, rec *httptest.ResponseRecorder) LevelStatus {
+	t.Helper()
+	var status LevelStatus
+	if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	return status
+}
+
+func TestLevelHandler_Get(t *testing.T) {
+	logger, _ := newHandlerLogger(t)
+
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log/level", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", ct)
+	}
+
+	status := decodeStatus(t, rec)
+	if status.Level != "info" || status.Namespace != "Admin" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if status.Config == nil || status.Config.Output != "console" || status.Config.Format != "plain" || !status.Config.ShowCaller {
+		t.Errorf("Unexpected effective config: %+v", status.Config)
+	}
+	if status.Namespaces["Admin"] != "info" {
+		t.Errorf("Expected own namespace in namespace levels, got %v", status.Namespaces)
+	}
+}
+
+func TestLevelHandler_PutJSON(t *testing.T) {
+	logger, buf := newHandlerLogger(t)
+
+	req := httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level":"debug"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected debug level, got %v", logger.GetLevel())
+	}
+	if status := decodeStatus(t, rec); status.Level != "debug" {
+		t.Errorf("Expected response to show new level, got %q", status.Level)
+	}
+	if output := stripANSI(buf.String()); !strings.Contains(output, "Level changed") || !strings.Contains(output, "to=debug") {
+		t.Errorf("Expected level change to be logged, got: %s", buf.String())
+	}
+}
+
+func TestLevelHandler_PostForm(t *testing.T) {
+	logger, buf := newHandlerLogger(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/log/level?level=warn", nil)
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if logger.GetLevel() != logrus.WarnLevel {
+		t.Errorf("Expected warn level, got %v", logger.GetLevel())
+	}
+	if !strings.HasPrefix(stripANSI(buf.String()), "WARN") {
+		t.Errorf("Expected change to be reported at the new level, got: %s", buf.String())
+	}
+}
+
+func TestLevelHandler_Namespace(t *testing.T) {
+	logger, _ := newHandlerLogger(t)
+
+	child := Child(logger, "Auth")
+	child.SetOutput(&bytes.Buffer{})
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"trace","namespace":"Admin/Auth"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if child.GetLevel() != logrus.TraceLevel {
+		t.Errorf("Expected child level trace, got %v", child.GetLevel())
+	}
+	if logger.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected parent level unchanged, got %v", logger.GetLevel())
+	}
+	if status := decodeStatus(t, rec); status.Namespaces["Admin/Auth"] != "trace" {
+		t.Errorf("Expected namespace level in status, got %v", status.Namespaces)
+	}
+}
+
+func TestLevelHandler_TagLevels(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+	logger := New(WithNamespace("Admin"), WithoutCaller(), WithTagLevels(map[string]logrus.Level{"DB": logrus.DebugLevel}))
+	t.Cleanup(func() { unregister(logger) })
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if status := decodeStatus(t, rec); status.Level != "info" {
+		t.Errorf("Expected the configured level, not the one the tags need, got %q", status.Level)
+	}
+
+	rec = httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/?level=trace", nil))
+	if status := decodeStatus(t, rec); status.Level != "trace" {
+		t.Errorf("Expected the new level, got %q", status.Level)
+	}
+	logger.Trace("untagged detail")
+	if out := stripANSI(buf.String()); !strings.Contains(out, "from=info") || !strings.Contains(out, "to=trace") || !strings.Contains(out, "untagged detail") {
+		t.Errorf("Expected the change reported and untagged trace lines shown, got: %s", out)
+	}
+
+	buf.Reset()
+	LevelHandler(logger).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/?level=warn", nil))
+	logger.Info("untagged info")
+	logger.Debug("[DB] query")
+	if out := buf.String(); strings.Contains(out, "untagged info") || !strings.Contains(out, "query") {
+		t.Errorf("Expected the tag level kept after raising the level, got: %s", out)
+	}
+	if GetLevel(logger) != logrus.WarnLevel || logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected level warn behind a debug gate, got %v and %v", GetLevel(logger), logger.GetLevel())
+	}
+}
+
+func TestLevelHandler_Errors(t *testing.T) {
+	logger, _ := newHandlerLogger(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		code   int
+	}{
+		{"bad level", http.MethodPut, "/?level=loud", "", http.StatusBadRequest},
+		{"missing level", http.MethodPut, "/", "", http.StatusBadRequest},
+		{"bad json", http.MethodPut, "/", "{", http.StatusBadRequest},
+		{"body too large", http.MethodPut, "/", `{"level":"debug","namespace":"` + strings.Repeat("x", maxLevelRequestSize) + `"}`, http.StatusBadRequest},
+		{"unknown namespace", http.MethodPut, "/?level=debug&namespace=Nope", "", http.StatusNotFound},
+		{"method", http.MethodDelete, "/", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			rec := httptest.NewRecorder()
+			LevelHandler(logger).ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("Expected %d, got %d: %s", tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	if logger.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected failed requests to leave the level alone, got %v", logger.GetLevel())
+	}
+}
+
+func TestLevelHandler_ResolvedOutput(t *testing.T) {
+	t.Setenv("LOG_OUTPUT", "file")
+	t.Setenv("LOG_FILE", "")
+	logger := New()
+	t.Cleanup(func() { unregister(logger) })
+
+	// Changing the env later does not change what the logger writes to
+	t.Setenv("LOG_FILE", "other.log")
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if cfg := decodeStatus(t, rec).Config; cfg == nil || cfg.Output != "console" || cfg.Filename != "" {
+		t.Errorf("Expected the console fallback the logger uses, got %+v", cfg)
+	}
+}
+
+func TestLevelHandler_UnregisteredLogger(t *testing.T) {
+	logger := logrus.New()
+
+	rec := httptest.NewRecorder()
+	LevelHandler(logger).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	status := decodeStatus(t, rec)
+	if status.Level != "info" || status.Config != nil {
+		t.Errorf("Expected level only for plain logrus loggers, got %+v", status)
+	}
+}
@@ -1,14 +1,28 @@
 package pretty
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/sirupsen/logrus"
 )
 
 // Option is a function that modifies our Config
 type Option func(*Config)
 
-// New creates a logger by applying functional options to a default config
+// New creates a logger by applying functional options to a default config.
+// Call Shutdown before exiting to write queued entries and close log files.
 func New(opts ...Option) *logrus.Logger {
+	cfg := newConfig(opts...)
+
+	l := logrus.New()
+	register(l, setup(l, *cfg))
+	registerExitHandler()
+	return l
+}
+
+// newConfig builds the config New uses: defaults first, then the options in order
+func newConfig(opts ...Option) *Config {
 	// 1. Set defaults
 	plain := FormatPlain
 	console := OutputConsole
@@ -18,20 +32,25 @@ func New(opts ...Option) *logrus.Logger {
 			Output:     &console,
 			ShowCaller: true,
 		},
-		Namespace: "Main",
-		EnvLevel:  "LOG_LEVEL",
-		EnvOutput: "LOG_OUTPUT",
-		EnvFormat: "LOG_FORMAT",
+		Namespace:    "Main",
+		EnvLevel:     "LOG_LEVEL",
+		EnvOutput:    "LOG_OUTPUT",
+		EnvFormat:    "LOG_FORMAT",
+		EnvTagLevels: "LOG_TAG_LEVELS",
+		EnvTheme:     "LOG_THEME",
+
+		EnvFile:       "LOG_FILE",
+		EnvMaxSize:    "LOG_MAX_SIZE",
+		EnvMaxBackups: "LOG_MAX_BACKUPS",
+		EnvMaxAge:     "LOG_MAX_AGE",
+		EnvCompress:   "LOG_COMPRESS",
 	}
 
 	// 2. Apply user overrides
 	for _, opt := range opts {
 		opt(cfg)
 	}
-
-	l := logrus.New()
-	setup(l, *cfg)
-	return l
+	return cfg
 }
 
 // --- Options functions ---
@@ -56,10 +75,78 @@ func WithNamespace(name string) Option {
 	return func(c *Config) { c.Namespace = name }
 }
 
+// WithFile sets the log file for OutputFile and OutputMulti.
+//
+// Overrides the LOG_FILE env var.
 func WithFile(path string) Option {
 	return func(c *Config) { c.Filename = path }
 }
 
+// WithFileRotation sets how the log file is rotated. The config is used as
+// given, so zero MaxBackups and MaxAge keep every file; start from
+// DefaultLogFileConfig() to change only some fields:
+//
+//	rotation := pretty.DefaultLogFileConfig()
+//	rotation.Interval = pretty.RotateDaily
+//	log := pretty.New(pretty.WithOutput(pretty.OutputFile), pretty.WithFile("logs/app.log"),
+//	    pretty.WithFileRotation(rotation))
+//
+// Overrides the LOG_MAX_SIZE, LOG_MAX_BACKUPS, LOG_MAX_AGE and LOG_COMPRESS env vars.
+func WithFileRotation(config LogFileConfig) Option {
+	return func(c *Config) {
+		c.Rotation = &config
+		c.fileRotation = nil // Overrides a config file given before
+	}
+}
+
+// WithTagLevels sets the most verbose level logged for each bracket tag, e.g.
+// {"DB": logrus.DebugLevel, "HTTP": logrus.WarnLevel} keeps "[DB]" debug lines
+// while hiding "[HTTP]" info lines. The "*" key sets the level for untagged
+// messages and other tags; without it they use the logger level.
+//
+// The logrus level of the logger becomes a gate lowered to the most verbose tag
+// level, so l.GetLevel and l.IsLevelEnabled report the gate; see SetLevel.
+//
+// Overrides the LOG_TAG_LEVELS env var ("DB=debug,HTTP=warn,*=info").
+func WithTagLevels(levels map[string]logrus.Level) Option {
+	return func(c *Config) { c.TagLevels = levels }
+}
+
+// WithThemeName colors the plain formatter with a built-in or registered theme:
+// dark (default), light, solarized, high-contrast or monochrome.
+//
+// Overrides the LOG_THEME env var. Formatters given with WithCustomFormat keep
+// their own Theme if they set one.
+func WithThemeName(name string) Option {
+	return func(c *Config) {
+		t, ok := LookupTheme(name)
+		if !ok {
+			c.err = fmt.Errorf("unknown theme %q (want %s)", name, strings.Join(ThemeNames(), ", "))
+			return
+		}
+		c.Theme = t
+	}
+}
+
 func WithoutCaller() Option {
 	return func(c *Config) { c.ShowCaller = false }
 }
+
+// WithConfigFile applies the settings from a YAML, JSON or TOML file (see FileConfig).
+// Options passed after it override the file. A file that fails to load is reported
+// on stderr and ignored, so a bad config never prevents logging.
+func WithConfigFile(path string) Option {
+	return func(c *Config) {
+		c.ConfigFile = path
+
+		fc, err := LoadConfig(path)
+		if err != nil {
+			c.err = err
+			return
+		}
+		opts, _ := fc.Options()
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
@@ -4,11 +4,26 @@ import (
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// LogFileConfig sets how a log file is rotated. A zero LogFileConfig stands
+// for DefaultLogFileConfig(); any other is used as given.
 type LogFileConfig struct {
-	MaxSize    int  `default:"10"`
-	MaxBackups int  `default:"5"`
-	MaxAge     int  `default:"31"`
-	Compress   bool `default:"true"`
+	MaxSize    int // Megabytes per file, 0 for lumberjack's 100 or no limit with Interval
+	MaxBackups int // Rotated files kept, 0 keeps all
+	MaxAge     int // Days rotated files are kept, 0 keeps them forever
+	Compress   bool
+
+	// Interval also rotates at every hour or day boundary, see RotatingFile
+	Interval RotateInterval
+	// UTC aligns Interval and the names of files to UTC instead of local time
+	UTC bool
+	// Pattern names the files, relative to the directory of the log file.
+	// %Y, %m, %d and %H are replaced by the start of the period, e.g.
+	// "service-%Y-%m-%d.log". Empty inserts the date before the extension of
+	// the log file name.
+	Pattern string
+	// MaxTotalSize removes the oldest rotated files once all files together
+	// exceed this many megabytes. 0 removes none.
+	MaxTotalSize int
 }
 
 func NewLumberjackLogger(logFileName string, config LogFileConfig) *lumberjack.Logger {
@@ -30,6 +45,15 @@ func DefaultLogFileConfig() LogFileConfig {
 	}
 }
 
+// withDefaults returns DefaultLogFileConfig() for a zero config and config
+// otherwise, so zero fields keep their meaning once any field is set
+func (config LogFileConfig) withDefaults() LogFileConfig {
+	if config == (LogFileConfig{}) {
+		return DefaultLogFileConfig()
+	}
+	return config
+}
+
 func NewLogFileConfig(maxSize, maxBackups, maxAge int, compress bool) LogFileConfig {
 	return LogFileConfig{
 		MaxSize:    maxSize,
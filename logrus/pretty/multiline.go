@@ -0,0 +1,47 @@
+package pretty
+
+import "strings"
+
+// MultilineMode defines how messages containing newlines are written
+type MultilineMode int
+
+const (
+	MultilineVerbatim MultilineMode = iota // Written as is, continuation lines start at column 0
+	MultilineIndent                        // Continuation lines start at the message column
+	MultilineEscape                        // Newlines written as \n, one line per entry for file sinks
+)
+
+// WithMultiline sets how messages with newlines are written. The gutter, e.g.
+// "│ ", is put in front of every continuation line in MultilineIndent mode.
+func WithMultiline(mode MultilineMode, gutter string) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.Multiline = mode
+		f.MultilineGutter = gutter
+	}
+}
+
+// multilineNames are the modes accepted by name in config files
+var multilineNames = map[string]MultilineMode{
+	"indent":   MultilineIndent,
+	"escape":   MultilineEscape,
+	"verbatim": MultilineVerbatim,
+}
+
+// formatMultiline lays out the lines of message. indent is the width of
+// everything before the message column.
+func (f *CustomFormatter) formatMultiline(message, indent string) string {
+	if !strings.ContainsAny(message, "\r\n") || f.Multiline == MultilineVerbatim {
+		return message
+	}
+
+	message = strings.TrimRight(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	if f.Multiline == MultilineEscape {
+		return strings.NewReplacer("\n", `\n`, "\r", `\r`).Replace(message)
+	}
+
+	gutter := f.MultilineGutter
+	if gutter != "" && f.UseColors {
+		gutter = f.palette().caller + gutter + ColorReset
+	}
+	return strings.ReplaceAll(message, "\n", "\n"+indent+gutter)
+}
@@ -0,0 +1,60 @@
+package pretty
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMultiline_IndentsToMessageColumn(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithMultiline(MultilineIndent, ""))
+	out := formatEntry(t, f, logrus.InfoLevel, "[SQL] query:\r\nSELECT *\n  FROM users\n", nil)
+
+	indent := strings.Repeat(" ", 23)
+	want := "INFO   [SQL]           query:\n" +
+		indent + "SELECT *\n" +
+		indent + "  FROM users\n"
+	if out != want {
+		t.Errorf("Expected indented continuation lines\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestMultiline_Gutter(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithTimestamp(true), WithMultiline(MultilineIndent, "│ "))
+	out := formatEntry(t, f, logrus.InfoLevel, "[Dump] payload\n{\"id\": 1}", nil)
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected two lines, got %q", out)
+	}
+	col := strings.Index(lines[0], "payload")
+	if lines[1] != strings.Repeat(" ", col)+"│ {\"id\": 1}" {
+		t.Errorf("Expected the gutter at the message column %d, got %q", col, lines[1])
+	}
+}
+
+func TestMultiline_EscapeAndVerbatim(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithMultiline(MultilineEscape, ""))
+	out := formatEntry(t, f, logrus.InfoLevel, "[Panic] boom\ngoroutine 1\r\n", nil)
+	if out != "INFO   [Panic]         boom\\ngoroutine 1\n" {
+		t.Errorf("Expected one escaped line, got %q", out)
+	}
+
+	f.Multiline = MultilineVerbatim
+	out = formatEntry(t, f, logrus.InfoLevel, "[Panic] boom\ngoroutine 1", nil)
+	if out != "INFO   [Panic]         boom\ngoroutine 1\n" {
+		t.Errorf("Expected the message as is, got %q", out)
+	}
+}
+
+func TestMultiline_FieldsAfterLastLine(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithMultiline(MultilineIndent, ""))
+	entry := logrus.NewEntry(logrus.New()).WithField("rows", 2)
+	entry.Message = "a\nb"
+	entry.Level = logrus.InfoLevel
+	b, _ := f.Format(entry)
+	if !strings.HasSuffix(string(b), "b rows=2\n") {
+		t.Errorf("Expected fields after the last line, got %q", b)
+	}
+}
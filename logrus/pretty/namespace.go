@@ -0,0 +1,155 @@
+package pretty
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NamespaceKey is the field that carries the logger namespace on every entry.
+// CustomFormatter renders it as a column; JSON and raw output show it as a field.
+// A "namespace" field set by the caller is kept, and the namespace is then only
+// shown by CustomFormatter and JSONFormatter.
+const NamespaceKey = "namespace"
+
+// entryMeta is what loggerHook records about an entry outside its fields
+type entryMeta struct {
+	namespace string
+	stamped   bool         // Data[NamespaceKey] holds the namespace, not a field of the caller
+	order     []string     // Key order recorded by Ordered, moved out of the fields
+	level     logrus.Level // Level of the logger for untagged entries, see GetLevel
+	held      bool         // Above level or its tag level, only let through by the gate for other tags or sinks
+}
+
+type metaKey struct{}
+
+// metaOf returns the meta loggerHook recorded for e, or nil for entries of
+// loggers not created by New or Child
+func metaOf(e *logrus.Entry) *entryMeta {
+	if e.Context == nil {
+		return nil
+	}
+	m, _ := e.Context.Value(metaKey{}).(*entryMeta)
+	return m
+}
+
+// namespaceOf returns the namespace of the logger that created e, and whether
+// e.Data[NamespaceKey] holds it. Without meta the field is taken as the namespace.
+func namespaceOf(e *logrus.Entry) (ns string, inData bool) {
+	if m := metaOf(e); m != nil {
+		return m.namespace, m.stamped
+	}
+	ns, inData = e.Data[NamespaceKey].(string)
+	return ns, inData
+}
+
+// loggerHook is the first hook of every logger created by New or Child. It
+// records the logger's namespace in the entry context for our formatters and
+// stamps it on the fields for the others. The order recorded by Ordered moves
+// into the context too, so no output writes it as a field.
+//
+// It also holds the logger's level: l.Level is only the gate, lowered when
+// tags need a more verbose level, see SetLevel. Entries the gate lets through
+// beyond that level are marked held, so hooks added with AddHook skip them.
+type loggerHook struct {
+	namespace string
+	level     atomic.Uint32             // Set with SetLevel
+	floor     atomic.Uint32             // The most verbose level any tag or sink needs
+	gate      atomic.Uint32             // The logrus level SetLevel last gave the logger
+	tags      atomic.Pointer[tagFilter] // The logger's tag levels, nil without any
+	root      *logrus.Logger            // The logger created by New a child derives from, nil for that logger
+}
+
+func (h *loggerHook) Levels() []logrus.Level { return logrus.AllLevels }
+func (h *loggerHook) Fire(e *logrus.Entry) error {
+	m := &entryMeta{namespace: h.namespace, level: h.visible(e.Logger)}
+	limit := m.level
+	if f := h.tags.Load(); f != nil {
+		if lvl, ok := f.tagLevel(e); ok {
+			limit = lvl
+		}
+	}
+	m.held = e.Level > limit
+	if order, ok := e.Data[FieldOrderKey].([]string); ok {
+		m.order = order
+		delete(e.Data, FieldOrderKey) // e.Data is the entry's own copy
+	}
+	if _, taken := e.Data[NamespaceKey]; !taken && h.namespace != "" {
+		e.Data[NamespaceKey] = h.namespace
+		m.stamped = true
+	}
+
+	ctx := e.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	e.Context = context.WithValue(ctx, metaKey{}, m)
+	return nil
+}
+
+// setNamespace makes name the namespace of l. The hook goes first so that
+// output hooks registered later already see the field. Levels carry over from
+// the hook l has, which for a child is the parent's.
+func setNamespace(l *logrus.Logger, name string) {
+	h := &loggerHook{namespace: name}
+	if old := hookOf(l); old != nil {
+		h.level.Store(uint32(old.visible(l)))
+		h.floor.Store(old.floor.Load())
+		h.tags.Store(old.tags.Load())
+		h.gate.Store(uint32(l.GetLevel()))
+		h.root = old.root
+	}
+
+	hooks := make(logrus.LevelHooks)
+	hooks.Add(h)
+	for level, list := range l.Hooks {
+		for _, h := range list {
+			if _, ok := h.(*loggerHook); !ok {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	l.ReplaceHooks(hooks)
+}
+
+// Child derives a logger whose namespace is nested under the parent's, e.g.
+// Child(log, "Auth") on a "Main" logger logs as "Main/Auth".
+//
+// The child shares the parent's formatter, output and hooks, so it writes to the
+// same console and files, but starts with a copy of the parent's level that can
+// be changed independently (see also LevelHandler). LevelHandler finds children
+// through the logger created by New; a child that is no longer used is
+// garbage collected like any other value.
+func Child(parent *logrus.Logger, name string) *logrus.Logger {
+	root := parent
+	if h := hookOf(parent); h != nil {
+		if h.namespace != "" {
+			name = h.namespace + "/" + name
+		}
+		if h.root != nil {
+			root = h.root
+		}
+	}
+
+	child := logrus.New()
+	child.SetFormatter(parent.Formatter)
+	child.SetOutput(parent.Out)
+	child.SetReportCaller(parent.ReportCaller)
+	child.SetLevel(parent.GetLevel())
+	child.ExitFunc = parent.ExitFunc
+	child.ReplaceHooks(copyHooks(parent.Hooks))
+	setNamespace(child, name)
+	hookOf(child).root = root
+
+	addChild(root, child)
+	return child
+}
+
+func copyHooks(hooks logrus.LevelHooks) logrus.LevelHooks {
+	copied := make(logrus.LevelHooks, len(hooks))
+	for level, list := range hooks {
+		copied[level] = append([]logrus.Hook(nil), list...)
+	}
+	return copied
+}
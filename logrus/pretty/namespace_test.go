@@ -0,0 +1,235 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFormatter_NamespaceColumn(t *testing.T) {
+	f := &CustomFormatter{ShowNamespace: true, NamespacePadding: 10, BracketPadding: 15}
+	entry := &logrus.Entry{
+		Message: "[Auth] Login ok",
+		Level:   logrus.InfoLevel,
+		Data:    logrus.Fields{NamespaceKey: "Main/Auth", "user": "alice"},
+	}
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+
+	expected := "INFO   Main/Auth  [Auth]          Login ok user=alice\n"
+	if string(b) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(b))
+	}
+}
+
+func TestFormatter_NamespaceHiddenFromFields(t *testing.T) {
+	f := &CustomFormatter{ShowNamespace: false}
+	entry := &logrus.Entry{
+		Message: "hello",
+		Level:   logrus.InfoLevel,
+		Data:    logrus.Fields{NamespaceKey: "Main"},
+	}
+
+	b, _ := f.Format(entry)
+	if strings.Contains(string(b), "Main") || strings.Contains(string(b), "namespace=") {
+		t.Errorf("Expected namespace to be neither a column nor a field, got %q", string(b))
+	}
+}
+
+func TestFormatter_NamespaceLongerThanPadding(t *testing.T) {
+	f := &CustomFormatter{ShowNamespace: true, NamespacePadding: 4}
+	entry := &logrus.Entry{Message: "msg", Level: logrus.InfoLevel, Data: logrus.Fields{NamespaceKey: "Service/Worker"}}
+
+	b, _ := f.Format(entry)
+	if !strings.HasPrefix(string(b), "INFO   Service/Worker ") {
+		t.Errorf("Expected long namespace to widen its column, got %q", string(b))
+	}
+}
+
+func TestFormatter_NamespaceCallerIndent(t *testing.T) {
+	f := &CustomFormatter{ShowNamespace: true, NamespacePadding: 10, BracketPadding: 15, ShowCaller: true, CallerLevel: logrus.WarnLevel}
+	l := logrus.New()
+	l.SetReportCaller(true)
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	l.SetFormatter(f)
+	l.WithField(NamespaceKey, "Main").Error("[DB] down")
+
+	lines := strings.Split(buf.String(), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected caller line, got %q", buf.String())
+	}
+	messageCol := strings.Index(lines[0], "down")
+	callerCol := strings.Index(lines[1], "└─")
+	if messageCol != callerCol {
+		t.Errorf("Expected caller aligned with message column %d, got %d", messageCol, callerCol)
+	}
+}
+
+func TestNew_NamespaceInOutput(t *testing.T) {
+	logger := New(WithNamespace("App"), WithFormat(FormatPlain), WithOutput(OutputConsole))
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	logger.Info("[Server] started")
+
+	if !strings.Contains(stripANSI(buf.String()), "App") {
+		t.Errorf("Expected namespace column in output, got: %s", buf.String())
+	}
+}
+
+func TestNew_NamespaceJSONField(t *testing.T) {
+	logger := New(WithNamespace("App"), WithFormat(FormatJSON))
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	logger.Info("started")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if entry[NamespaceKey] != "App" {
+		t.Errorf("Expected namespace field in JSON, got %v", entry)
+	}
+}
+
+func TestNew_KeepsNamespaceField(t *testing.T) {
+	decode := func(buf *bytes.Buffer) map[string]any {
+		t.Helper()
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		return entry
+	}
+
+	var buf bytes.Buffer
+	logger := New(WithNamespace("App"), WithFormat(FormatJSON))
+	logger.SetOutput(&buf)
+	logger.WithField(NamespaceKey, "kube-system").Info("pod started")
+	if entry := decode(&buf); entry[NamespaceKey] != "kube-system" {
+		t.Errorf("Expected the caller's namespace field in JSON, got %v", entry)
+	}
+
+	buf.Reset()
+	logger = New(WithNamespace("App"), WithFormat(FormatJSONTagged))
+	logger.SetOutput(&buf)
+	logger.WithField(NamespaceKey, "kube-system").Info("pod started")
+	if entry := decode(&buf); entry[NamespaceKey] != "App" || entry["fields."+NamespaceKey] != "kube-system" {
+		t.Errorf("Expected the logger namespace and the caller's field, got %v", entry)
+	}
+
+	buf.Reset()
+	logger = New(WithNamespace("App"), WithFormat(FormatPlain), WithOutput(OutputConsole), WithoutCaller())
+	logger.SetOutput(&buf)
+	logger.WithField(NamespaceKey, "kube-system").Info("pod started")
+	if out := stripANSI(buf.String()); !strings.Contains(out, "App") || !strings.Contains(out, "namespace=kube-system") {
+		t.Errorf("Expected the namespace column and the caller's field, got %q", out)
+	}
+}
+
+func TestChild_NestedNamespace(t *testing.T) {
+	root := New(WithNamespace("Main"), WithFormat(FormatJSON))
+	var buf bytes.Buffer
+	root.SetOutput(&buf)
+
+	auth := Child(root, "Auth")
+	token := Child(auth, "Token")
+	defer unregister(root)
+
+	auth.Info("from auth")
+	token.Info("from token")
+	root.Info("from root")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines on the shared output, got %q", buf.String())
+	}
+
+	expected := []string{"Main/Auth", "Main/Auth/Token", "Main"}
+	for i, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Invalid JSON: %v", err)
+		}
+		if entry[NamespaceKey] != expected[i] {
+			t.Errorf("Line %d: expected namespace %q, got %v", i, expected[i], entry[NamespaceKey])
+		}
+	}
+}
+
+func TestChild_OwnLevel(t *testing.T) {
+	root := New(WithLevel(logrus.InfoLevel))
+	child := Child(root, "Sub")
+	defer unregister(root)
+
+	if child.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected child to start with the parent level, got %v", child.GetLevel())
+	}
+
+	child.SetLevel(logrus.DebugLevel)
+	if root.GetLevel() != logrus.InfoLevel {
+		t.Errorf("Expected parent level unaffected, got %v", root.GetLevel())
+	}
+	if child.Formatter != root.Formatter || child.Out != root.Out {
+		t.Error("Expected child to share formatter and output with its parent")
+	}
+}
+
+func TestChild_SharesMultiOutput(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	root := New(WithOutput(OutputMulti), WithFile(logFile), WithNamespace("Main"))
+	child := Child(root, "Jobs")
+	defer unregister(root)
+
+	child.Info("[Cron] tick")
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "Main/Jobs") || !strings.Contains(string(data), "tick") {
+		t.Errorf("Expected child entry with namespace in shared file, got: %s", data)
+	}
+}
+
+func TestChild_LevelHandlerNamespace(t *testing.T) {
+	root := New(WithNamespace("Main"))
+	child := Child(root, "Auth")
+	child.SetOutput(&bytes.Buffer{})
+	defer unregister(root)
+
+	req := httptest.NewRequest(http.MethodPut, "/?level=trace&namespace=Main/Auth", nil)
+	rec := httptest.NewRecorder()
+	LevelHandler(root).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if child.GetLevel() != logrus.TraceLevel {
+		t.Errorf("Expected child level trace, got %v", child.GetLevel())
+	}
+}
+
+func TestChild_UnregisteredParent(t *testing.T) {
+	parent := logrus.New()
+	child := Child(parent, "Solo")
+
+	if h := hookOf(child); h == nil || h.namespace != "Solo" {
+		t.Errorf("Expected plain name for child of an unregistered logger, got %+v", h)
+	}
+	if _, ok := lookup(child); ok {
+		t.Error("Expected no registry entry for a child of an unregistered logger")
+	}
+}
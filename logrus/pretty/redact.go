@@ -0,0 +1,337 @@
+package pretty
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedactStrategy defines what a secret is replaced with
+type RedactStrategy int
+
+const (
+	RedactFull    RedactStrategy = iota // [REDACTED]
+	RedactPartial                       // ****1234, or j***@example.com for emails
+	RedactHash                          // sha256:5e884898da28, the same secret gives the same hash
+)
+
+// RedactedMask replaces secrets with RedactFull
+const RedactedMask = "[REDACTED]"
+
+// RedactPattern finds secrets in field values and messages
+type RedactPattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+	// Valid optionally checks a match, e.g. the Luhn checksum of a card number
+	Valid func(match string) bool
+}
+
+// Built-in patterns, all enabled by NewRedactor
+var (
+	PatternJWT = RedactPattern{
+		Name:   "jwt",
+		Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	}
+	PatternCreditCard = RedactPattern{
+		Name:   "credit_card",
+		Regexp: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Valid:  luhnValid,
+	}
+	PatternEmail = RedactPattern{
+		Name:   "email",
+		Regexp: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`),
+	}
+	PatternAWSKey = RedactPattern{
+		Name:   "aws_access_key",
+		Regexp: regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA)[0-9A-Z]{16}\b`),
+	}
+)
+
+// DefaultRedactKeys are the field names masked by NewRedactor
+var DefaultRedactKeys = []string{
+	"password", "passwd", "secret", "token", "api_key", "authorization",
+	"cookie", "set_cookie", "private_key", "client_secret",
+}
+
+// Redactor masks secrets in entries before they reach any formatter. It is a
+// logrus hook; New installs it ahead of the output hooks, see WithRedaction.
+//
+// Fields are masked when their name matches Keys, and string values, errors
+// and the message are searched for Patterns. Nested maps, slices and the
+// exported fields of structs are searched as well; struct fields go by their
+// json name, the same key the formatters print. A struct with a secret is
+// replaced by a map of its fields.
+type Redactor struct {
+	// Keys are matched case-insensitively, with "-" equal to "_". A key also
+	// matches as a prefix or suffix, so "token" masks "access_token".
+	Keys     []string
+	Patterns []RedactPattern
+	Strategy RedactStrategy
+	// HashKey turns RedactHash into an HMAC, so short secrets can't be guessed
+	// from their hash
+	HashKey []byte
+}
+
+// NewRedactor returns a Redactor with DefaultRedactKeys and all built-in patterns
+func NewRedactor(strategy RedactStrategy) *Redactor {
+	return &Redactor{
+		Keys:     append([]string(nil), DefaultRedactKeys...),
+		Patterns: []RedactPattern{PatternJWT, PatternCreditCard, PatternEmail, PatternAWSKey},
+		Strategy: strategy,
+	}
+}
+
+// WithRedaction masks secrets with r before any output is written. Child
+// loggers inherit it.
+func WithRedaction(r *Redactor) Option {
+	return func(c *Config) {
+		c.Redactor = r
+	}
+}
+
+func (r *Redactor) Levels() []logrus.Level { return logrus.AllLevels }
+
+// Fire redacts the message and fields of e in place. logrus hands hooks a copy
+// of the fields, so the caller's map is not changed.
+func (r *Redactor) Fire(e *logrus.Entry) error {
+	e.Message = r.Redact(e.Message)
+	_, nsInData := namespaceOf(e)
+	for k, v := range e.Data {
+		if k == NamespaceKey && nsInData || k == FieldOrderKey {
+			continue
+		}
+		e.Data[k] = r.redactField(k, v)
+	}
+	return nil
+}
+
+// Redact replaces the secrets found by the patterns in s
+func (r *Redactor) Redact(s string) string {
+	for _, p := range r.Patterns {
+		s = p.Regexp.ReplaceAllStringFunc(s, func(match string) string {
+			if p.Valid != nil && !p.Valid(match) {
+				return match
+			}
+			return r.mask(match)
+		})
+	}
+	return s
+}
+
+func (r *Redactor) redactField(key string, v any) any {
+	return r.redactValue(key, v, 0)
+}
+
+func (r *Redactor) redactValue(key string, v any, depth int) any {
+	if r.sensitiveKey(key) {
+		if v == nil {
+			return v
+		}
+		return r.mask(fmt.Sprint(v))
+	}
+
+	switch x := v.(type) {
+	case string:
+		return r.Redact(x)
+	case []byte:
+		if s := string(x); r.Redact(s) != s {
+			return r.Redact(s)
+		}
+	case error, fmt.Stringer:
+		// Only replaced when there is a secret, so error trees stay intact otherwise
+		s := fmt.Sprint(x)
+		if redacted := r.Redact(s); redacted != s {
+			return redacted
+		}
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, v := range x {
+			out[k] = r.redactValue(k, v, depth+1)
+		}
+		return out
+	case logrus.Fields:
+		out := make(logrus.Fields, len(x))
+		for k, v := range x {
+			out[k] = r.redactValue(k, v, depth+1)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(x))
+		for k, v := range x {
+			out[k] = r.redactValue(k, v, depth+1).(string)
+		}
+		return out
+	case []string:
+		out := make([]string, len(x))
+		for i, s := range x {
+			out[i] = r.Redact(s)
+		}
+		return out
+	case []any:
+		out := make([]any, len(x))
+		for i, v := range x {
+			out[i] = r.redactValue("", v, depth+1)
+		}
+		return out
+	default:
+		if out, ok := r.redactStruct(reflect.ValueOf(v), depth); ok {
+			return out
+		}
+	}
+	return v
+}
+
+// redactStruct returns the exported fields of a struct, or a pointer to one,
+// as a map keyed by json name. ok is false when no field was redacted, so
+// structs without secrets are kept as they are.
+func (r *Redactor) redactStruct(rv reflect.Value, depth int) (out map[string]any, ok bool) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct || depth >= maxFlattenDepth {
+		return nil, false
+	}
+	out = make(map[string]any, rv.NumField())
+	return out, r.redactStructFields(out, rv, depth)
+}
+
+// redactStructFields adds the redacted fields of rv to out and reports
+// whether any of them changed
+func (r *Redactor) redactStructFields(out map[string]any, rv reflect.Value, depth int) (changed bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := sf.Name
+		if tag, _, _ := strings.Cut(sf.Tag.Get("json"), ","); tag == "-" {
+			continue
+		} else if tag != "" {
+			name = tag
+		}
+		fv := rv.Field(i)
+		if sf.Anonymous && fv.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			// Embedded structs share the parent's keys, as in the formatters
+			changed = r.redactStructFields(out, fv, depth) || changed
+			continue
+		}
+		v := fv.Interface()
+		out[name] = r.redactValue(name, v, depth+1)
+		changed = changed || !reflect.DeepEqual(v, out[name])
+	}
+	return changed
+}
+
+// sensitiveKey reports whether a field name matches one of the Keys
+func (r *Redactor) sensitiveKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	for _, k := range r.Keys {
+		k = strings.ReplaceAll(strings.ToLower(k), "-", "_")
+		if key == k || strings.HasSuffix(key, "_"+k) || strings.HasPrefix(key, k+"_") || strings.HasSuffix(key, "."+k) {
+			return true
+		}
+	}
+	return false
+}
+
+// mask replaces a secret according to the strategy
+func (r *Redactor) mask(secret string) string {
+	switch r.Strategy {
+	case RedactPartial:
+		if at := strings.LastIndexByte(secret, '@'); at > 0 && !strings.ContainsAny(secret, " \t") {
+			return secret[:1] + "***" + secret[at:]
+		}
+		digits := strings.Map(func(r rune) rune {
+			if r == ' ' || r == '-' {
+				return -1
+			}
+			return r
+		}, secret)
+		if len(digits) < 12 {
+			return "****"
+		}
+		return "****" + digits[len(digits)-4:]
+	case RedactHash:
+		var sum []byte
+		if len(r.HashKey) > 0 {
+			mac := hmac.New(sha256.New, r.HashKey)
+			mac.Write([]byte(secret))
+			sum = mac.Sum(nil)
+		} else {
+			h := sha256.Sum256([]byte(secret))
+			sum = h[:]
+		}
+		return "sha256:" + hex.EncodeToString(sum)[:12]
+	default:
+		return RedactedMask
+	}
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum
+func luhnValid(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
+
+// redactorOf returns the Redactor installed on l, if any
+func redactorOf(l *logrus.Logger) *Redactor {
+	for _, h := range l.Hooks[logrus.InfoLevel] {
+		if r, ok := h.(*Redactor); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// setRedactor puts r in front of the output hooks, after the logger hook, so
+// output hooks such as multi output only see redacted entries
+func setRedactor(l *logrus.Logger, r *Redactor) {
+	hooks := make(logrus.LevelHooks)
+	for level, list := range l.Hooks {
+		for _, h := range list {
+			if _, ok := h.(*loggerHook); ok {
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	if r != nil {
+		hooks.Add(r)
+	}
+	for level, list := range l.Hooks {
+		for _, h := range list {
+			switch h.(type) {
+			case *loggerHook, *Redactor:
+			default:
+				hooks[level] = append(hooks[level], h)
+			}
+		}
+	}
+	l.ReplaceHooks(hooks)
+}
@@ -0,0 +1,130 @@
+package pretty
+
+import (
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"weak"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loggerInfo is what the package remembers about a logger created by New
+type loggerInfo struct {
+	cfg      Config
+	children []weak.Pointer[logrus.Logger] // Derived with Child, pruned as they are collected
+}
+
+// registry tracks loggers created by New so runtime tools such as LevelHandler
+// can find their config and children. Loggers are held weakly: an entry goes
+// away once its logger is garbage collected. Children are not registered; they
+// are found through the entry of the logger they derive from.
+var registry = struct {
+	sync.RWMutex
+	loggers map[weak.Pointer[logrus.Logger]]*loggerInfo
+}{loggers: map[weak.Pointer[logrus.Logger]]*loggerInfo{}}
+
+func register(l *logrus.Logger, cfg Config) {
+	key := weak.Make(l)
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.loggers[key]; !ok {
+		runtime.AddCleanup(l, forget, key)
+	}
+	registry.loggers[key] = &loggerInfo{cfg: cfg}
+}
+
+func unregister(l *logrus.Logger) {
+	forget(weak.Make(l))
+}
+
+// forget removes the entry of a logger, called once it is garbage collected
+func forget(key weak.Pointer[logrus.Logger]) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.loggers, key)
+}
+
+// addChild records child under the entry of root, if root is registered
+func addChild(root, child *logrus.Logger) {
+	registry.Lock()
+	defer registry.Unlock()
+	info, ok := registry.loggers[weak.Make(root)]
+	if !ok {
+		return
+	}
+	if len(info.children) == cap(info.children) {
+		// Prune before the slice grows, so it stays within twice the live children
+		info.children = slices.DeleteFunc(info.children, func(c weak.Pointer[logrus.Logger]) bool {
+			return c.Value() == nil
+		})
+	}
+	info.children = append(info.children, weak.Make(child))
+}
+
+// rootOf returns the logger created by New that l derives from, and l's
+// namespace if l is a child
+func rootOf(l *logrus.Logger) (root *logrus.Logger, namespace string) {
+	if h := hookOf(l); h != nil && h.root != nil {
+		return h.root, h.namespace
+	}
+	return l, ""
+}
+
+// lookup returns a copy of the info registered for l. A child gets the info
+// of its root with its own namespace.
+func lookup(l *logrus.Logger) (loggerInfo, bool) {
+	root, namespace := rootOf(l)
+
+	registry.RLock()
+	defer registry.RUnlock()
+	info, ok := registry.loggers[weak.Make(root)]
+	if !ok {
+		return loggerInfo{}, false
+	}
+	copied := loggerInfo{cfg: info.cfg}
+	if root != l {
+		copied.cfg.Namespace = namespace
+	}
+	return copied, true
+}
+
+// updateConfig replaces the config recorded for l, e.g. after a reload
+func updateConfig(l *logrus.Logger, cfg Config) {
+	registry.Lock()
+	defer registry.Unlock()
+	if info, ok := registry.loggers[weak.Make(l)]; ok {
+		info.cfg = cfg
+	}
+}
+
+// family returns l and every live logger derived from it, keyed by namespace
+func family(l *logrus.Logger) map[string]*logrus.Logger {
+	root, namespace := rootOf(l)
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	members := map[string]*logrus.Logger{}
+	info, ok := registry.loggers[weak.Make(root)]
+	if !ok {
+		return members
+	}
+	if root == l {
+		members[info.cfg.Namespace] = l
+	} else {
+		members[namespace] = l
+	}
+	for _, c := range info.children {
+		child := c.Value()
+		if child == nil || child == l {
+			continue
+		}
+		h := hookOf(child)
+		if root == l || strings.HasPrefix(h.namespace, namespace+"/") {
+			members[h.namespace] = child
+		}
+	}
+	return members
+}
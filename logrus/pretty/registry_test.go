@@ -0,0 +1,123 @@
+package pretty
+
+import (
+	"runtime"
+	"testing"
+	"time"
+	"weak"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRegistry_RegisterLookup(t *testing.T) {
+	l := logrus.New()
+	register(l, Config{Namespace: "Reg"})
+	defer unregister(l)
+
+	info, ok := lookup(l)
+	if !ok {
+		t.Fatal("Expected registered logger to be found")
+	}
+	if info.cfg.Namespace != "Reg" {
+		t.Errorf("Expected namespace Reg, got %q", info.cfg.Namespace)
+	}
+
+	updateConfig(l, Config{Namespace: "Updated"})
+	if info, _ := lookup(l); info.cfg.Namespace != "Updated" {
+		t.Errorf("Expected updated namespace, got %q", info.cfg.Namespace)
+	}
+
+	unregister(l)
+	if _, ok := lookup(l); ok {
+		t.Error("Expected logger to be gone after unregister")
+	}
+}
+
+func TestRegistry_Family(t *testing.T) {
+	root := New(WithNamespace("Root"))
+	other := New(WithNamespace("Other"))
+	child := Child(root, "Auth")
+	grandchild := Child(child, "Token")
+	sibling := Child(root, "Jobs")
+	defer unregister(root)
+	defer unregister(other)
+
+	members := family(root)
+	if len(members) != 4 {
+		t.Fatalf("Expected root, both children and grandchild, got %v", members)
+	}
+	if members["Root/Auth/Token"] != grandchild || members["Root/Jobs"] != sibling {
+		t.Error("Expected every descendant to be part of the family")
+	}
+	if _, ok := members["Other"]; ok {
+		t.Error("Expected unrelated logger to be excluded")
+	}
+
+	if members := family(child); len(members) != 2 || members["Root/Auth"] != child {
+		t.Errorf("Expected child family to have 2 members, got %v", members)
+	}
+	if info, ok := lookup(grandchild); !ok || info.cfg.Namespace != "Root/Auth/Token" {
+		t.Errorf("Expected the root's config with the child's namespace, got %+v", info.cfg)
+	}
+}
+
+func TestRegistry_ChildrenNotRegistered(t *testing.T) {
+	root := New(WithNamespace("Root"))
+	defer unregister(root)
+
+	registry.RLock()
+	before := len(registry.loggers)
+	registry.RUnlock()
+
+	var keys []weak.Pointer[logrus.Logger]
+	for range 100 {
+		keys = append(keys, weak.Make(Child(root, "Request")))
+	}
+
+	registry.RLock()
+	after := len(registry.loggers)
+	registry.RUnlock()
+	if after > before {
+		t.Errorf("Expected children to stay out of the registry, got %d entries instead of %d", after, before)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		runtime.GC()
+		live := 0
+		for _, k := range keys {
+			if k.Value() != nil {
+				live++
+			}
+		}
+		if live == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected unused children to be collected, %d still live", live)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if members := family(root); len(members) != 1 {
+		t.Errorf("Expected only the root once its children are collected, got %v", members)
+	}
+}
+
+func TestRegistry_CollectedLoggersForgotten(t *testing.T) {
+	key := weak.Make(New(WithNamespace("Gone"), WithOutput(OutputConsole)))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		runtime.GC()
+		registry.RLock()
+		_, ok := registry.loggers[key]
+		registry.RUnlock()
+		if !ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the entry of a collected logger to be removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
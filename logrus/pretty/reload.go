@@ -0,0 +1,355 @@
+package pretty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultReloadInterval is how often a Reloader checks its config file for changes
+const DefaultReloadInterval = 2 * time.Second
+
+// Reloader re-applies a logger's configuration at runtime. It re-runs the same
+// options given to New, so env vars and WithConfigFile are read again, and swaps
+// the level, formatter, writers and redaction of the existing logger and its
+// children in place.
+//
+// The first Reload routes the logger and its children through the Reloader: it
+// replaces their multi-output hook, or becomes their Formatter, and their Out
+// is io.Discard. Entries are then formatted and written inside a single call
+// holding the current configuration, so no entry is ever split between the old
+// and new configuration.
+type Reloader struct {
+	logger   *logrus.Logger
+	opts     []Option
+	interval time.Duration
+
+	reloadMu sync.Mutex   // Serializes Reload calls
+	mu       sync.RWMutex // Held for writing while swapping, for reading while logging
+	state    *reloadState
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// reloadState is one resolved configuration: either a single formatter and writer,
+// or a MultiWriter for OutputMulti
+type reloadState struct {
+	cfg       Config
+	floor     logrus.Level // The most verbose level the tag and sink levels need
+	tags      *tagFilter   // nil without tag levels
+	formatter logrus.Formatter
+	out       io.Writer
+	mw        *MultiWriter
+}
+
+// NewReloader creates a Reloader for a logger built with New(opts...).
+// Pass the same options so the reloaded config matches the original one.
+// Nothing changes until Reload or Start is called.
+func NewReloader(l *logrus.Logger, opts ...Option) *Reloader {
+	return &Reloader{
+		logger:   l,
+		opts:     opts,
+		interval: DefaultReloadInterval,
+	}
+}
+
+// SetInterval sets how often Start polls the config file. Must be called before Start.
+func (r *Reloader) SetInterval(d time.Duration) {
+	if d > 0 {
+		r.interval = d
+	}
+}
+
+// Reload resolves the options again and swaps the result into the logger and
+// its children. If the config file fails to load, the current configuration is kept.
+func (r *Reloader) Reload() error {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	cfg := newConfig(r.opts...)
+	if cfg.err != nil {
+		return cfg.err
+	}
+
+	next := resolveState(*cfg)
+	members := r.members()
+
+	if r.state == nil {
+		// Route every logger through the Reloader before anything changes,
+		// still writing with the current config, so the swap below is the
+		// only switch between the old and new writers
+		current := currentState(r.logger)
+		r.mu.Lock()
+		r.state = current
+		r.mu.Unlock()
+		for _, l := range members {
+			r.install(l)
+		}
+	}
+	for _, l := range members {
+		if redactorOf(l) != next.cfg.Redactor {
+			setRedactor(l, next.cfg.Redactor)
+		}
+	}
+
+	r.mu.Lock()
+	prev := r.state
+	if prev.mw != nil {
+		// Write what the old async queues hold before next takes over, so
+		// entries keep their order
+		prev.mw.closeQueues(context.Background())
+	}
+	r.state = next
+	r.mu.Unlock()
+
+	// The logger setters take the logger's own lock, which entries hold while
+	// calling Format, so they must run after r.mu is released
+	r.applyLevels(members, next)
+	updateConfig(r.logger, next.cfg)
+
+	// No entry can be using prev anymore, so its files are safe to close
+	closeStateFiles(prev, next)
+	return nil
+}
+
+// members returns the logger and its live children
+func (r *Reloader) members() []*logrus.Logger {
+	members := []*logrus.Logger{r.logger}
+	for _, l := range family(r.logger) {
+		if l != r.logger {
+			members = append(members, l)
+		}
+	}
+	return members
+}
+
+// install routes l through the Reloader: in place of its multi-output hook if
+// it has one, otherwise as its formatter. Either is a single swap, so no entry
+// is written twice or lost. Children created later copy the Reloader.
+func (r *Reloader) install(l *logrus.Logger) {
+	if reloaderOf(l) == r {
+		return
+	}
+
+	hooks := make(logrus.LevelHooks)
+	replaced := false
+	for level, list := range l.Hooks {
+		for _, h := range list {
+			if _, ok := h.(*CustomHook); ok {
+				h, replaced = r, true
+			}
+			hooks[level] = append(hooks[level], h)
+		}
+	}
+	if replaced {
+		l.ReplaceHooks(hooks)
+	} else {
+		l.SetFormatter(r)
+	}
+	l.SetOutput(io.Discard)
+}
+
+// applyLevels applies the level and caller setting of s. The logger gets the
+// configured level; children keep theirs, with the gate the new tag and sink
+// levels need.
+func (r *Reloader) applyLevels(members []*logrus.Logger, s *reloadState) {
+	if h := hookOf(r.logger); h == nil || h.namespace != s.cfg.Namespace {
+		setNamespace(r.logger, s.cfg.Namespace)
+	}
+	for _, l := range members {
+		h := hookOf(l)
+		level := h.visible(l)
+		if l == r.logger {
+			level = *s.cfg.Level
+		}
+		h.floor.Store(uint32(s.floor))
+		h.tags.Store(s.tags)
+		SetLevel(l, level)
+		l.SetReportCaller(s.cfg.reportCaller())
+	}
+}
+
+// reloaderOf returns the Reloader l writes through, if any
+func reloaderOf(l *logrus.Logger) *Reloader {
+	if r, ok := l.Formatter.(*Reloader); ok {
+		return r
+	}
+	for _, h := range l.Hooks[logrus.InfoLevel] {
+		if r, ok := h.(*Reloader); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+func (r *Reloader) Levels() []logrus.Level { return logrus.AllLevels }
+
+// Fire writes the entry with the current configuration, for loggers where the
+// Reloader replaced the multi-output hook
+func (r *Reloader) Fire(e *logrus.Entry) error {
+	_, err := r.Format(e)
+	return err
+}
+
+// Format writes the entry with the current configuration and returns nothing,
+// since the logger's own output is discarded
+func (r *Reloader) Format(e *logrus.Entry) ([]byte, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s := r.state
+	if s.mw != nil {
+		return nil, s.mw.WriteEntry(e)
+	}
+
+	buf, err := s.formatter.Format(e)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.out.Write(buf); err != nil {
+		fmt.Fprintf(os.Stderr, "log write err: %v\n", err)
+	}
+	return nil, nil
+}
+
+// Start reloads on SIGHUP and whenever the config file changes.
+// It returns immediately; call Stop to end watching.
+func (r *Reloader) Start() {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+
+	path := newConfig(r.opts...).ConfigFile
+	last := statConfigFile(path)
+
+	go func() {
+		defer close(r.done)
+		defer signal.Stop(sighup)
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-sighup:
+				r.reloadAndReport("SIGHUP")
+			case <-ticker.C:
+				if path == "" {
+					continue
+				}
+				if cur := statConfigFile(path); cur != last {
+					last = cur
+					r.reloadAndReport(path + " changed")
+				}
+			}
+		}
+	}()
+}
+
+// Stop ends watching started by Start and waits for the watcher to exit
+func (r *Reloader) Stop() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop = nil
+}
+
+func (r *Reloader) reloadAndReport(reason string) {
+	if err := r.Reload(); err != nil {
+		r.logger.WithError(err).Errorf("[Logger] Reload after %s failed, keeping current config", reason)
+		return
+	}
+	r.logger.Infof("[Logger] Reloaded after %s - Level: %s", reason, GetLevel(r.logger))
+}
+
+// resolveState builds level, formatter and writers for cfg on a scratch logger,
+// reusing exactly the same logic as New
+func resolveState(cfg Config) *reloadState {
+	scratch := logrus.New()
+	setNamespace(scratch, cfg.Namespace)
+	cfg = cfg.apply(scratch)
+
+	s := currentState(scratch)
+	cfg.Level = s.cfg.Level
+	s.cfg = cfg
+	s.floor = logrus.Level(hookOf(scratch).floor.Load())
+	s.tags = hookOf(scratch).tags.Load()
+	return s
+}
+
+// currentState captures the formatter and writers a logger is using right now
+func currentState(l *logrus.Logger) *reloadState {
+	level := GetLevel(l)
+	s := &reloadState{cfg: Config{FormatterOptions: FormatterOptions{Level: &level}}, formatter: l.Formatter, out: l.Out}
+	for _, h := range l.Hooks[logrus.InfoLevel] {
+		if ch, ok := h.(*CustomHook); ok {
+			s.mw = ch.mw
+		}
+	}
+	return s
+}
+
+// reportCaller mirrors the ReportCaller decision made by setFormatter
+func (c Config) reportCaller() bool {
+	if c.CustomFormat != nil {
+		return c.CustomFormat.ShowCaller
+	}
+	return c.ShowCaller
+}
+
+// closeStateFiles closes the log files of prev that next no longer uses.
+// Only files opened by this package are closed; stdout and user writers are left alone.
+func closeStateFiles(prev, next *reloadState) {
+	inUse := map[io.Writer]bool{next.out: true}
+	if next.mw != nil {
+		for _, p := range next.mw.pairs {
+			inUse[p.w] = true
+		}
+	}
+
+	writers := []io.Writer{prev.out}
+	if prev.mw != nil {
+		for _, p := range prev.mw.pairs {
+			writers = append(writers, p.w)
+		}
+	}
+	for _, w := range writers {
+		if inUse[w] {
+			continue
+		}
+		if err := closeLogFile(w); err != nil && !errors.Is(err, os.ErrClosed) {
+			fmt.Fprintf(os.Stderr, "log close err: %v\n", err)
+		}
+	}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func statConfigFile(path string) fileStamp {
+	if path == "" {
+		return fileStamp{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
@@ -0,0 +1,256 @@
+package pretty
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func reloadConfigFile(t *testing.T, path, logFile, extra string) {
+	t.Helper()
+	content := "output: file\nfilename: " + logFile + "\n" + extra
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestReloader_ReloadFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	logger := New()
+
+	r := NewReloader(logger)
+	t.Setenv("LOG_LEVEL", "debug")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected debug level after reload, got %v", logger.GetLevel())
+	}
+	if _, ok := logger.Formatter.(*Reloader); !ok {
+		t.Error("Expected the Reloader to be installed as formatter")
+	}
+	if logger.Out != io.Discard {
+		t.Error("Expected logger output to be discarded after reload")
+	}
+}
+
+func TestReloader_ReloadFromFile(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.yaml")
+	logFile := filepath.Join(dir, "app.log")
+	reloadConfigFile(t, cfgPath, logFile, "level: info\n")
+
+	opts := []Option{WithConfigFile(cfgPath)}
+	logger := New(opts...)
+	r := NewReloader(logger, opts...)
+
+	logger.Debug("[Before] hidden")
+	logger.Info("[Before] visible")
+
+	reloadConfigFile(t, cfgPath, logFile, "level: debug\nformatter:\n  tag_style: right\n  padding_char: \"-\"\n")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	logger.Debug("[After] now visible")
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	output := string(data)
+
+	if strings.Contains(output, "hidden") {
+		t.Errorf("Expected debug entry before reload to be filtered, got: %s", output)
+	}
+	if !strings.Contains(output, "visible") {
+		t.Errorf("Expected info entry before reload, got: %s", output)
+	}
+	if !strings.Contains(output, "[After]------") {
+		t.Errorf("Expected right-styled tag after reload, got: %s", output)
+	}
+}
+
+func TestReloader_InvalidFileKeepsConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.yaml")
+	reloadConfigFile(t, cfgPath, filepath.Join(dir, "app.log"), "level: warn\n")
+
+	opts := []Option{WithConfigFile(cfgPath)}
+	logger := New(opts...)
+	r := NewReloader(logger, opts...)
+
+	reloadConfigFile(t, cfgPath, filepath.Join(dir, "app.log"), "level: loud\n")
+	if err := r.Reload(); err == nil {
+		t.Error("Expected error for invalid config file")
+	}
+	if logger.GetLevel() != logrus.WarnLevel {
+		t.Errorf("Expected level to stay warn, got %v", logger.GetLevel())
+	}
+}
+
+func TestReloader_ReplacesMultiHook(t *testing.T) {
+	dir := t.TempDir()
+	opts := []Option{WithOutput(OutputMulti), WithFile(filepath.Join(dir, "app.log"))}
+	logger := New(opts...)
+
+	if err := NewReloader(logger, opts...).Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	for _, hooks := range logger.Hooks {
+		for _, h := range hooks {
+			if _, ok := h.(*CustomHook); ok {
+				t.Error("Expected CustomHook to be removed once the Reloader writes multi output")
+			}
+		}
+	}
+}
+
+func TestReloader_ChildCreatedBefore(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.yaml")
+	oldFile, newFile := filepath.Join(dir, "old.log"), filepath.Join(dir, "new.log")
+	reloadConfigFile(t, cfgPath, oldFile, "level: info\n")
+
+	opts := []Option{WithConfigFile(cfgPath), WithNamespace("Main")}
+	logger := New(opts...)
+	child := Child(logger, "Jobs")
+	r := NewReloader(logger, opts...)
+
+	reloadConfigFile(t, cfgPath, newFile, "level: warn\nformatter:\n  tag_style: right\n  padding_char: \"-\"\n")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	child.Info("[Cron] tick")
+	logger.Info("[Root] hidden")
+
+	data, err := os.ReadFile(newFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if out := string(data); !strings.Contains(out, "Main/Jobs") || !strings.Contains(out, "[Cron]------") || strings.Contains(out, "hidden") {
+		t.Errorf("Expected the child on the new file and formatter, at its own level, got: %s", out)
+	}
+	if old, _ := os.ReadFile(oldFile); strings.Contains(string(old), "tick") {
+		t.Errorf("Expected nothing written to the old file after the reload, got: %s", old)
+	}
+	if reloaderOf(child) != r || GetLevel(child) != logrus.InfoLevel {
+		t.Errorf("Expected the child routed through the Reloader with its own level, got %v", GetLevel(child))
+	}
+}
+
+func TestReloader_KeepsRedaction(t *testing.T) {
+	dir := t.TempDir()
+	logFile := filepath.Join(dir, "app.log")
+	opts := []Option{WithOutput(OutputMulti), WithFile(logFile), WithRedaction(NewRedactor(RedactFull))}
+	logger := New(opts...)
+	child := Child(logger, "Auth")
+	r := NewReloader(logger, opts...)
+
+	for range 2 {
+		if err := r.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+	}
+	logger.WithField("password", "hunter2").Info("[Auth] login")
+	child.WithField("token", "s3cret").Info("[Auth] refresh")
+	Child(logger, "Late").WithField("api_key", "k3y").Info("[Auth] late")
+	if err := Shutdown(logger, context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	out := string(data)
+	for _, secret := range []string{"hunter2", "s3cret", "k3y"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("Expected %q redacted after reload, got: %s", secret, out)
+		}
+	}
+	if strings.Count(out, RedactedMask) != 3 {
+		t.Errorf("Expected 3 redacted fields, got: %s", out)
+	}
+}
+
+func TestReloader_StartWatchesFile(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.yaml")
+	logFile := filepath.Join(dir, "app.log")
+	reloadConfigFile(t, cfgPath, logFile, "level: info\n")
+
+	opts := []Option{WithConfigFile(cfgPath)}
+	logger := New(opts...)
+	r := NewReloader(logger, opts...)
+	r.SetInterval(10 * time.Millisecond)
+	r.Start()
+	defer r.Stop()
+
+	reloadConfigFile(t, cfgPath, logFile, "level: trace\n")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for logger.GetLevel() != logrus.TraceLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected file change to trigger reload, level is %v", logger.GetLevel())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReloader_ConcurrentLogging(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "logging.yaml")
+	logFile := filepath.Join(dir, "app.log")
+	reloadConfigFile(t, cfgPath, logFile, "level: info\n")
+
+	opts := []Option{WithConfigFile(cfgPath)}
+	logger := New(opts...)
+	r := NewReloader(logger, opts...)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				logger.Info("[Worker] tick")
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		style := []string{"default", "right", "center"}[i%3]
+		reloadConfigFile(t, cfgPath, logFile, "level: info\nformatter:\n  tag_style: "+style+"\n")
+		if err := r.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 800 {
+		t.Errorf("Expected 800 entries without drops, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "INFO") || !strings.HasSuffix(line, "tick") {
+			t.Fatalf("Expected whole entries only, got line: %q", line)
+		}
+	}
+}
+
+func TestReloader_StopWithoutStart(t *testing.T) {
+	r := NewReloader(logrus.New())
+	r.Stop() // Must not block or panic
+}
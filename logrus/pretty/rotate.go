@@ -0,0 +1,443 @@
+package pretty
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const megabyte = 1024 * 1024
+
+// RotateInterval aligns rotation to wall-clock boundaries
+type RotateInterval int
+
+const (
+	RotateNever  RotateInterval = iota // Size-based only
+	RotateHourly                       // At the start of every hour
+	RotateDaily                        // At midnight
+)
+
+// rotateIntervalNames are the intervals accepted by name in config files
+var rotateIntervalNames = map[string]RotateInterval{
+	"none":   RotateNever,
+	"hourly": RotateHourly,
+	"daily":  RotateDaily,
+}
+
+// NewLogFile opens the log file for config: a lumberjack.Logger for
+// size-based rotation, or a RotatingFile when config sets Interval, Pattern
+// or MaxTotalSize.
+func NewLogFile(filename string, config LogFileConfig) (io.WriteCloser, error) {
+	if config.Interval == RotateNever && config.Pattern == "" && config.MaxTotalSize == 0 {
+		return NewLumberjackLogger(filename, config), nil
+	}
+	return NewRotatingFile(filename, config)
+}
+
+// RotatingFile is a log file that starts a new file at every hour or day
+// boundary and whenever MaxSize is reached. Files are never renamed: each
+// one is named by the pattern when it is opened, and parts of the same period
+// get a number before the extension:
+//
+//	service-2026-10-16.log
+//	service-2026-10-16.1.log
+//	service-2026-10-17.log
+//
+// Rotated files are compressed and removed in the background according to
+// MaxBackups, MaxAge and MaxTotalSize.
+type RotatingFile struct {
+	dir     string
+	pattern string // File name pattern, see LogFileConfig.Pattern
+	cfg     LogFileConfig
+	loc     *time.Location
+	now     func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	name   string    // Path of the open file
+	size   int64     // Bytes in the open file
+	period time.Time // Start of the period of the open file
+	part   int
+
+	millMu sync.Mutex     // Serializes compression and removal
+	mills  sync.WaitGroup // Running mills, waited for by Close
+}
+
+// NewRotatingFile creates a RotatingFile for filename. The file is opened on
+// the first write. It fails if config.Pattern is not valid, see checkPattern.
+func NewRotatingFile(filename string, config LogFileConfig) (*RotatingFile, error) {
+	if err := checkPattern(config.Pattern, config.Interval); err != nil {
+		return nil, fmt.Errorf("log file pattern: %w", err)
+	}
+	loc := time.Local
+	if config.UTC {
+		loc = time.UTC
+	}
+	pattern := config.Pattern
+	if pattern == "" {
+		pattern = defaultPattern(filepath.Base(filename), config.Interval)
+	}
+	return &RotatingFile{
+		dir:     filepath.Dir(filename),
+		pattern: pattern,
+		cfg:     config,
+		loc:     loc,
+		now:     time.Now,
+	}, nil
+}
+
+// checkPattern reports a pattern that is not a plain file name, or that has
+// no placeholder for interval and so would reopen the same file at every
+// rotation or return to a file of an earlier month or year. An empty pattern
+// is valid.
+func checkPattern(pattern string, interval RotateInterval) error {
+	if pattern == "" {
+		return nil
+	}
+	if strings.ContainsAny(pattern, `/\`) {
+		return fmt.Errorf("must be a file name, got %q", pattern)
+	}
+	has := func(verbs ...string) bool {
+		for _, v := range verbs {
+			if !strings.Contains(pattern, v) {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case interval == RotateDaily && !has("%Y", "%m", "%d"):
+		return fmt.Errorf("%q needs %%Y, %%m and %%d for daily rotation", pattern)
+	case interval == RotateHourly && !has("%Y", "%m", "%d", "%H"):
+		return fmt.Errorf("%q needs %%Y, %%m, %%d and %%H for hourly rotation", pattern)
+	}
+	return nil
+}
+
+// defaultPattern inserts the date before the extension of base
+func defaultPattern(base string, interval RotateInterval) string {
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	switch interval {
+	case RotateDaily:
+		return stem + "-%Y-%m-%d" + ext
+	case RotateHourly:
+		return stem + "-%Y-%m-%d-%H" + ext
+	default:
+		return base
+	}
+}
+
+// Write writes p to the current file, rotating first if the period has ended
+// or p would exceed MaxSize
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	switch {
+	case r.file == nil:
+		if err := r.openExisting(now); err != nil {
+			return 0, err
+		}
+	case r.cfg.Interval != RotateNever && !r.periodStart(now).Equal(r.period):
+		if err := r.rotate(r.periodStart(now), 0); err != nil {
+			return 0, err
+		}
+	}
+
+	if limit := int64(r.cfg.MaxSize) * megabyte; limit > 0 && r.size > 0 && r.size+int64(len(p)) > limit {
+		if err := r.rotate(r.period, r.part+1); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file and waits for running compression and
+// removal. A later Write opens the file again.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	err := r.closeFile()
+	r.mu.Unlock()
+	r.mills.Wait()
+	return err
+}
+
+// Filename returns the path of the file being written, or "" before the first write
+func (r *RotatingFile) Filename() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.name
+}
+
+func (r *RotatingFile) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// periodStart returns the start of the hour or day containing t
+func (r *RotatingFile) periodStart(t time.Time) time.Time {
+	t = t.In(r.loc)
+	switch r.cfg.Interval {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, r.loc)
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.loc)
+	default:
+		return t
+	}
+}
+
+// openExisting continues the last part of the current period, if any. A
+// part counts whether it is still plain or already compressed; a compressed
+// last part is not reopened, the next part is started instead.
+func (r *RotatingFile) openExisting(now time.Time) error {
+	period := r.periodStart(now)
+	if r.cfg.Interval == RotateNever && !r.period.IsZero() {
+		period = r.period // Reopened after Close: keep the name
+	}
+	stat := func(part int) (plain, compressed bool) {
+		name := r.partName(period, part)
+		_, err := os.Stat(name)
+		_, gzErr := os.Stat(name + ".gz")
+		return err == nil, gzErr == nil
+	}
+	part := 0
+	for {
+		plain, compressed := stat(part + 1)
+		if !plain && !compressed {
+			break
+		}
+		part++
+	}
+	if plain, compressed := stat(part); !plain && compressed {
+		part++
+	}
+	return r.open(period, part)
+}
+
+// rotate closes the current file and opens the given part of period
+func (r *RotatingFile) rotate(period time.Time, part int) error {
+	if err := r.closeFile(); err != nil {
+		fmt.Fprintf(os.Stderr, "log rotate err: %v\n", err)
+	}
+	return r.open(period, part)
+}
+
+func (r *RotatingFile) open(period time.Time, part int) error {
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("can't make directory for log file: %w", err)
+	}
+	name := r.partName(period, part)
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("can't open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("can't stat log file: %w", err)
+	}
+
+	r.file, r.name, r.size = f, name, info.Size()
+	r.period, r.part = period, part
+
+	r.mills.Add(1)
+	go func() {
+		defer r.mills.Done()
+		r.mill()
+	}()
+	return nil
+}
+
+// partName returns the path of the given part of period
+func (r *RotatingFile) partName(period time.Time, part int) string {
+	name := expandPattern(r.pattern, period.In(r.loc))
+	if part > 0 {
+		ext := filepath.Ext(name)
+		name = strings.TrimSuffix(name, ext) + "." + strconv.Itoa(part) + ext
+	}
+	return filepath.Join(r.dir, name)
+}
+
+// expandPattern replaces %Y, %m, %d, %H and %% in pattern
+func expandPattern(pattern string, t time.Time) string {
+	return strings.NewReplacer(
+		"%Y", fmt.Sprintf("%04d", t.Year()),
+		"%m", fmt.Sprintf("%02d", int(t.Month())),
+		"%d", fmt.Sprintf("%02d", t.Day()),
+		"%H", fmt.Sprintf("%02d", t.Hour()),
+		"%%", "%",
+	).Replace(pattern)
+}
+
+// patternRegexp matches every file name pattern can produce, with or
+// without a part number and ".gz"
+func patternRegexp(pattern string) *regexp.Regexp {
+	ext := filepath.Ext(pattern)
+	stem := regexp.QuoteMeta(strings.TrimSuffix(pattern, ext))
+	stem = strings.NewReplacer("%Y", `\d{4}`, "%m", `\d{2}`, "%d", `\d{2}`, "%H", `\d{2}`, "%%", "%").Replace(stem)
+	return regexp.MustCompile(`^` + stem + `(\.\d+)?` + regexp.QuoteMeta(ext) + `(\.gz)?$`)
+}
+
+// logFileInfo is a file written by a RotatingFile, other than the open one
+type logFileInfo struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// mill compresses the rotated files and removes the ones beyond MaxBackups,
+// MaxAge and MaxTotalSize
+func (r *RotatingFile) mill() {
+	r.millMu.Lock()
+	defer r.millMu.Unlock()
+
+	// Read the name now rather than when the mill started, since later
+	// rotations may have happened meanwhile
+	files, currentSize, err := r.rotatedFiles(r.Filename())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "log rotate err: %v\n", err)
+		return
+	}
+
+	if r.cfg.Compress {
+		for i, f := range files {
+			if strings.HasSuffix(f.path, ".gz") {
+				continue
+			}
+			size, err := compressFile(f.path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "log compress err: %v\n", err)
+				continue
+			}
+			files[i].path, files[i].size = f.path+".gz", size
+		}
+	}
+
+	var cutoff time.Time
+	if r.cfg.MaxAge > 0 {
+		cutoff = r.now().Add(-time.Duration(r.cfg.MaxAge) * 24 * time.Hour)
+	}
+	total := currentSize
+	for i, f := range files {
+		total += f.size
+		expired := !cutoff.IsZero() && f.modTime.Before(cutoff)
+		tooMany := r.cfg.MaxBackups > 0 && i >= r.cfg.MaxBackups
+		tooBig := r.cfg.MaxTotalSize > 0 && total > int64(r.cfg.MaxTotalSize)*megabyte
+		if expired || tooMany || tooBig {
+			if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "log remove err: %v\n", err)
+			}
+			total -= f.size
+		}
+	}
+}
+
+// rotatedFiles lists the files matching the pattern other than current,
+// newest first, and the size of current
+func (r *RotatingFile) rotatedFiles(current string) ([]logFileInfo, int64, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	match := patternRegexp(r.pattern)
+
+	var files []logFileInfo
+	var currentSize int64
+	for _, e := range entries {
+		if e.IsDir() || !match.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue // Removed meanwhile
+		}
+		path := filepath.Join(r.dir, e.Name())
+		if path == current {
+			currentSize = info.Size()
+			continue
+		}
+		files = append(files, logFileInfo{path: path, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].modTime.Equal(files[j].modTime) {
+			return files[i].modTime.After(files[j].modTime)
+		}
+		return files[i].path > files[j].path
+	})
+	return files, currentSize, nil
+}
+
+// compressFile gzips path to path+".gz", keeping its modification time, and
+// removes path. It returns the compressed size. An existing path+".gz" is
+// never overwritten; path is then left as it is.
+func compressFile(path string) (int64, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return 0, err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return 0, err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return 0, err
+	}
+	if err := dst.Close(); err != nil {
+		return 0, err
+	}
+
+	if err := os.Chtimes(path+".gz", info.ModTime(), info.ModTime()); err != nil {
+		return 0, err
+	}
+	gzInfo, err := os.Stat(path + ".gz")
+	if err != nil {
+		return 0, err
+	}
+	return gzInfo.Size(), os.Remove(path)
+}
+
+// closeLogFile closes w if it is a log file opened by this package
+func closeLogFile(w io.Writer) error {
+	switch f := w.(type) {
+	case *lumberjack.Logger:
+		return f.Close()
+	case *RotatingFile:
+		return f.Close()
+	}
+	return nil
+}
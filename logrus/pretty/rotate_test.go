@@ -0,0 +1,212 @@
+package pretty
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// newTestRotatingFile returns a RotatingFile in a temp dir whose clock is *now
+func newTestRotatingFile(t *testing.T, cfg LogFileConfig, now *time.Time) (*RotatingFile, string) {
+	t.Helper()
+	dir := t.TempDir()
+	r, err := NewRotatingFile(filepath.Join(dir, "service.log"), cfg)
+	if err != nil {
+		t.Fatalf("NewRotatingFile error: %v", err)
+	}
+	r.now = func() time.Time { return *now }
+	t.Cleanup(func() { r.Close() })
+	return r, dir
+}
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir error: %v", err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestRotatingFile_Daily(t *testing.T) {
+	now := time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC)
+	r, dir := newTestRotatingFile(t, LogFileConfig{Interval: RotateDaily, UTC: true}, &now)
+
+	r.Write([]byte("late\n"))
+	now = now.Add(2 * time.Minute)
+	r.Write([]byte("early\n"))
+	r.Close()
+
+	got := strings.Join(dirNames(t, dir), ",")
+	if got != "service-2026-10-16.log,service-2026-10-17.log" {
+		t.Fatalf("Expected one file per day, got %s", got)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "service-2026-10-17.log"))
+	if string(data) != "early\n" {
+		t.Errorf("Expected the new day's entry in the new file, got %q", data)
+	}
+}
+
+func TestRotatingFile_HourlyUTCPattern(t *testing.T) {
+	now := time.Date(2026, 10, 16, 1, 30, 0, 0, time.FixedZone("EEST", 3*3600))
+	r, dir := newTestRotatingFile(t, LogFileConfig{Interval: RotateHourly, UTC: true, Pattern: "svc-%Y%m%d-%H.log"}, &now)
+
+	r.Write([]byte("x\n"))
+	if got := filepath.Base(r.Filename()); got != "svc-20261015-22.log" {
+		t.Errorf("Expected the UTC hour in the name, got %s (%v)", got, dirNames(t, dir))
+	}
+}
+
+func TestRotatingFile_SizeWithinPeriod(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	r, dir := newTestRotatingFile(t, LogFileConfig{Interval: RotateDaily, UTC: true, MaxSize: 1}, &now)
+
+	chunk := bytes.Repeat([]byte("a"), 600*1024)
+	r.Write(chunk)
+	r.Write(chunk)
+	r.Write(chunk)
+	r.Close()
+
+	got := strings.Join(dirNames(t, dir), ",")
+	if got != "service-2026-10-16.1.log,service-2026-10-16.2.log,service-2026-10-16.log" {
+		t.Fatalf("Expected numbered parts within the day, got %s", got)
+	}
+
+	// A restart continues the last part
+	r.Write([]byte("more\n"))
+	if got := filepath.Base(r.Filename()); got != "service-2026-10-16.2.log" {
+		t.Errorf("Expected the last part reopened, got %s", got)
+	}
+}
+
+func TestRotatingFile_Retention(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	r, dir := newTestRotatingFile(t, LogFileConfig{Interval: RotateDaily, UTC: true, MaxTotalSize: 1}, &now)
+
+	// Three older days of 400 KiB each, plus an unrelated file
+	for i, day := range []string{"13", "14", "15"} {
+		path := filepath.Join(dir, "service-2026-10-"+day+".log")
+		os.WriteFile(path, bytes.Repeat([]byte("a"), 400*1024), 0o600)
+		mod := now.Add(time.Duration(i-3) * 24 * time.Hour)
+		os.Chtimes(path, mod, mod)
+	}
+	os.WriteFile(filepath.Join(dir, "other.log"), []byte("keep"), 0o600)
+
+	r.Write(bytes.Repeat([]byte("a"), 300*1024))
+	r.Close()
+
+	got := strings.Join(dirNames(t, dir), ",")
+	if got != "other.log,service-2026-10-15.log,service-2026-10-16.log" {
+		t.Errorf("Expected the oldest days removed to stay under 1 MiB, got %s", got)
+	}
+}
+
+func TestRotatingFile_CompressAndMaxBackups(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	r, dir := newTestRotatingFile(t, LogFileConfig{Interval: RotateDaily, UTC: true, Compress: true, MaxBackups: 1}, &now)
+
+	for range 3 {
+		r.Write([]byte("entry\n"))
+		now = now.Add(24 * time.Hour)
+	}
+	r.Close()
+
+	got := strings.Join(dirNames(t, dir), ",")
+	if got != "service-2026-10-15.log.gz,service-2026-10-16.log" {
+		t.Errorf("Expected one compressed backup next to the current file, got %s", got)
+	}
+}
+
+func TestRotatingFile_RestartKeepsArchives(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cfg := LogFileConfig{Interval: RotateDaily, UTC: true, MaxSize: 1, Compress: true}
+	r, dir := newTestRotatingFile(t, cfg, &now)
+	for range 3 {
+		r.Write(bytes.Repeat([]byte("1"), 600*1024))
+	}
+	r.Close()
+
+	// A new RotatingFile, as after a restart or a reload
+	r, err := NewRotatingFile(filepath.Join(dir, "service.log"), cfg)
+	if err != nil {
+		t.Fatalf("NewRotatingFile error: %v", err)
+	}
+	r.now = func() time.Time { return now }
+	r.Write([]byte("2"))
+	if got := filepath.Base(r.Filename()); got != "service-2026-10-16.2.log" {
+		t.Errorf("Expected the last part continued, got %s (%v)", got, dirNames(t, dir))
+	}
+	r.Write(bytes.Repeat([]byte("2"), 600*1024))
+	r.Write(bytes.Repeat([]byte("2"), 600*1024))
+	r.Close()
+
+	for _, name := range []string{"service-2026-10-16.log.gz", "service-2026-10-16.1.log.gz"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected archive %s, got %v (%v)", name, err, dirNames(t, dir))
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("Invalid archive %s: %v", name, err)
+		}
+		data, _ := io.ReadAll(gz)
+		f.Close()
+		if len(data) != 600*1024 || data[0] != '1' {
+			t.Errorf("Expected %s to keep the first run, got %d bytes starting with %q", name, len(data), data[:1])
+		}
+	}
+}
+
+func TestRotatingFile_CompressedLastPart(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	r, dir := newTestRotatingFile(t, LogFileConfig{Interval: RotateDaily, UTC: true, Compress: true}, &now)
+	os.WriteFile(filepath.Join(dir, "service-2026-10-16.log.gz"), []byte("archive"), 0o600)
+
+	r.Write([]byte("new\n"))
+	if got := filepath.Base(r.Filename()); got != "service-2026-10-16.1.log" {
+		t.Errorf("Expected a new part after a compressed one, got %s", got)
+	}
+}
+
+func TestNewLogFile(t *testing.T) {
+	f, err := NewLogFile("app.log", DefaultLogFileConfig())
+	if _, ok := f.(*lumberjack.Logger); !ok || err != nil {
+		t.Errorf("Expected lumberjack for size-based rotation, got %T (%v)", f, err)
+	}
+	cfg := DefaultLogFileConfig()
+	cfg.Interval = RotateDaily
+	f, err = NewLogFile("app.log", cfg)
+	if _, ok := f.(*RotatingFile); !ok || err != nil {
+		t.Errorf("Expected a RotatingFile for daily rotation, got %T (%v)", f, err)
+	}
+}
+
+func TestNewRotatingFile_InvalidPattern(t *testing.T) {
+	for _, cfg := range []LogFileConfig{
+		{Pattern: "logs/service.log"},
+		{Pattern: "service.log", Interval: RotateDaily},
+		{Pattern: "service-%Y-%m-%d.log", Interval: RotateHourly},
+		{Pattern: "service-%d.log", Interval: RotateDaily},
+	} {
+		if _, err := NewRotatingFile("app.log", cfg); err == nil {
+			t.Errorf("Expected pattern %q with interval %d rejected", cfg.Pattern, cfg.Interval)
+		}
+	}
+
+	logger := New(WithOutput(OutputFile), WithFile(filepath.Join(t.TempDir(), "app.log")),
+		WithFileRotation(LogFileConfig{Pattern: "app.log", Interval: RotateDaily}))
+	if _, ok := logger.Out.(*lumberjack.Logger); !ok {
+		t.Errorf("Expected size-based rotation after an invalid pattern, got %T", logger.Out)
+	}
+}
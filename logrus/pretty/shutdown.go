@@ -0,0 +1,111 @@
+package pretty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ExitTimeout bounds the Shutdown that runs when a logger created by New
+// logs at Fatal level
+var ExitTimeout = 5 * time.Second
+
+// Shutdown writes what the async queues of l hold and closes its log files
+// and sinks. It returns ctx.Err() if the queues are not written in time; the
+// files are then left open, since a write may still be using them.
+//
+// Call it on the logger returned by New, not on a Child, which shares its
+// outputs. stdout and stderr are never closed. Entries logged after Shutdown
+// are written synchronously, reopening log files as needed.
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+//	defer cancel()
+//	pretty.Shutdown(log, ctx)
+//
+// Loggers created by New are shut down automatically when they log at Fatal
+// level, see ExitTimeout.
+func Shutdown(l *logrus.Logger, ctx context.Context) error {
+	mw := multiWriterOf(l)
+	if mw != nil {
+		if err := mw.closeQueues(ctx); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, w := range outputsOf(l, mw) {
+		c, ok := w.(io.Closer)
+		if !ok || w == os.Stdout || w == os.Stderr {
+			continue
+		}
+		if err := c.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// outputsOf returns the writers l writes to, without duplicates
+func outputsOf(l *logrus.Logger, mw *MultiWriter) []io.Writer {
+	out := l.Out
+	if r := reloaderOf(l); r != nil {
+		r.mu.RLock()
+		if r.state != nil {
+			out = r.state.out
+		}
+		r.mu.RUnlock()
+	}
+
+	writers := []io.Writer{out}
+	if mw != nil {
+		for _, p := range mw.pairs {
+			writers = append(writers, p.w)
+		}
+	}
+
+	seen := map[io.Writer]bool{}
+	unique := writers[:0]
+	for _, w := range writers {
+		if w != nil && !seen[w] {
+			seen[w] = true
+			unique = append(unique, w)
+		}
+	}
+	return unique
+}
+
+var exitHandlerOnce sync.Once
+
+// registerExitHandler makes logrus shut down every logger created by New
+// before a Fatal entry exits the program
+func registerExitHandler() {
+	exitHandlerOnce.Do(func() {
+		logrus.RegisterExitHandler(shutdownAll)
+	})
+}
+
+// shutdownAll shuts down the live loggers created by New
+func shutdownAll() {
+	registry.RLock()
+	var roots []*logrus.Logger
+	for key := range registry.loggers {
+		if l := key.Value(); l != nil {
+			roots = append(roots, l)
+		}
+	}
+	registry.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), ExitTimeout)
+	defer cancel()
+	for _, l := range roots {
+		if err := Shutdown(l, ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "log shutdown err: %v\n", err)
+		}
+	}
+}
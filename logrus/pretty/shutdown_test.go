@@ -0,0 +1,78 @@
+package pretty
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// closingWriter is a gatedWriter that records Close
+type closingWriter struct {
+	*gatedWriter
+	closed atomic.Bool
+}
+
+func (w *closingWriter) Close() error {
+	w.closed.Store(true)
+	return nil
+}
+
+func TestShutdown_DrainsAndCloses(t *testing.T) {
+	w := &closingWriter{gatedWriter: newGatedWriter()}
+	logger := New(WithSink(NewSink(w, nil)), WithSink(NewSink(os.Stdout, nil)), WithAsync(AsyncConfig{}))
+
+	logger.Info("queued")
+	<-w.started
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(w.release)
+	}()
+
+	if err := Shutdown(logger, context.Background()); err != nil {
+		t.Fatalf("Shutdown error: %v", err)
+	}
+	if !strings.Contains(w.String(), "queued") || !w.closed.Load() {
+		t.Errorf("Expected the entry written and the sink closed, got %q closed=%v", w.String(), w.closed.Load())
+	}
+	if _, err := os.Stdout.Stat(); err != nil {
+		t.Errorf("Expected stdout left open, got %v", err)
+	}
+
+	logger.Info("after")
+	if !strings.Contains(w.String(), "after") {
+		t.Errorf("Expected entries after Shutdown written directly, got %q", w.String())
+	}
+}
+
+func TestShutdown_Deadline(t *testing.T) {
+	w := &closingWriter{gatedWriter: newGatedWriter()}
+	logger := New(WithSink(NewSink(w, nil)), WithAsync(AsyncConfig{}))
+	defer close(w.release)
+
+	logger.Info("stuck")
+	<-w.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := Shutdown(logger, ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the deadline, got %v", err)
+	}
+	if w.closed.Load() {
+		t.Errorf("Expected the sink left open while a write is pending")
+	}
+}
+
+func TestShutdown_OnFatal(t *testing.T) {
+	w := &closingWriter{gatedWriter: newGatedWriter()}
+	close(w.release)
+	logger := New(WithSink(NewSink(w, nil)), WithAsync(AsyncConfig{}))
+	logger.ExitFunc = func(int) {}
+
+	logger.Fatal("[App] giving up")
+	if !strings.Contains(w.String(), "giving up") || !w.closed.Load() {
+		t.Errorf("Expected the exit handler to write and close the sink, got %q closed=%v", w.String(), w.closed.Load())
+	}
+}
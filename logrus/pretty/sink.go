@@ -0,0 +1,130 @@
+package pretty
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sink is one output of a logger: a writer with its own formatter, level range
+// and filter. Create sinks with NewSink and pass them to WithSink.
+type Sink struct {
+	Writer io.Writer
+	// Formatter formats entries for Writer. nil uses the logger's format, with
+	// colors when Writer is a terminal and timestamps when it is not stdout or stderr.
+	Formatter logrus.Formatter
+	// MostVerbose is the most verbose level written, e.g. DebugLevel. The zero
+	// value, PanicLevel, follows the logger level and its tag levels instead.
+	MostVerbose logrus.Level
+	// LeastVerbose is the least verbose level written, e.g. ErrorLevel to leave
+	// out Fatal and Panic. The zero value keeps every severe level.
+	LeastVerbose logrus.Level
+	// Filter drops the entries it returns false for. nil keeps all.
+	Filter func(*logrus.Entry) bool
+
+	dropped atomic.Uint64 // Entries dropped by the async queue, see Dropped
+}
+
+// SinkOption is a functional option for configuring a Sink
+type SinkOption func(*Sink)
+
+// NewSink creates a sink that writes to w with formatter f, which may be nil.
+// Without WithSinkLevel it writes what the logger level lets through.
+func NewSink(w io.Writer, f logrus.Formatter, opts ...SinkOption) *Sink {
+	s := &Sink{
+		Writer:    w,
+		Formatter: f,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithSinkLevel writes entries from mostVerbose up to leastVerbose, whatever
+// the logger level, e.g. WithSinkLevel(logrus.DebugLevel, logrus.PanicLevel)
+// for Debug and above
+func WithSinkLevel(mostVerbose, leastVerbose logrus.Level) SinkOption {
+	return func(s *Sink) {
+		s.MostVerbose = mostVerbose
+		s.LeastVerbose = leastVerbose
+	}
+}
+
+// WithSinkFilter only writes the entries keep returns true for
+func WithSinkFilter(keep func(*logrus.Entry) bool) SinkOption {
+	return func(s *Sink) {
+		s.Filter = keep
+	}
+}
+
+// WithSink sends entries to s. Sinks replace the outputs chosen by WithOutput,
+// so a logger with sinks writes to its sinks only. Sinks without a level
+// follow the logger level; the logger lets through what the sinks with their
+// own level need, see SetLevel.
+//
+//	log := pretty.New(
+//	    pretty.WithLevel(logrus.InfoLevel),
+//	    pretty.WithSink(pretty.NewSink(os.Stdout, nil)),
+//	    pretty.WithSink(pretty.NewSink(file, pretty.NewJSONFormatter(), pretty.WithSinkLevel(logrus.DebugLevel, logrus.PanicLevel))),
+//	    pretty.WithSink(pretty.NewSink(os.Stderr, nil, pretty.WithSinkLevel(logrus.ErrorLevel, logrus.PanicLevel))),
+//	)
+func WithSink(s *Sink) Option {
+	return func(c *Config) {
+		c.Sinks = append(c.Sinks, s)
+	}
+}
+
+// ownLevel reports whether the sink has a level of its own
+func (s *Sink) ownLevel() bool {
+	return s.MostVerbose != logrus.PanicLevel
+}
+
+// allows reports whether the sink writes e. filter holds the tag levels and
+// the logger level, which sinks without their own level follow; tag levels
+// apply to every sink.
+func (s *Sink) allows(e *logrus.Entry, filter *tagFilter) bool {
+	if e.Level < s.LeastVerbose {
+		return false
+	}
+	if s.ownLevel() {
+		if e.Level > s.MostVerbose {
+			return false
+		}
+		if filter != nil {
+			if lvl, ok := filter.tagLevel(e); ok && e.Level > lvl {
+				return false
+			}
+		}
+	} else if filter != nil && !filter.allows(e) {
+		return false
+	}
+	return s.Filter == nil || s.Filter(e)
+}
+
+// sinkLevel returns the most verbose level of the sinks with their own level,
+// PanicLevel if there are none
+func (c Config) sinkLevel() logrus.Level {
+	lvl := logrus.PanicLevel
+	for _, s := range c.Sinks {
+		lvl = max(lvl, s.MostVerbose)
+	}
+	return lvl
+}
+
+// AddSink adds a sink. A nil formatter is resolved like AddWriter would for
+// the sink's writer.
+func (mw *MultiWriter) AddSink(s *Sink) {
+	f := s.Formatter
+	if f == nil {
+		f = mw.formatterFor(s.Writer, true, !isConsole(s.Writer))
+	}
+	mw.addPair(s.Writer, f, s)
+}
+
+// Dropped returns the number of entries this sink dropped because its async
+// queue was full, see WithAsync
+func (s *Sink) Dropped() uint64 {
+	return s.dropped.Load()
+}
@@ -0,0 +1,115 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSink_LevelsAndFormatters(t *testing.T) {
+	var console, file, errs bytes.Buffer
+	logger := New(
+		WithLevel(logrus.DebugLevel),
+		WithoutCaller(),
+		WithSink(NewSink(&console, NewCustomFormatter(WithColors(false)), WithSinkLevel(logrus.InfoLevel, logrus.PanicLevel))),
+		WithSink(NewSink(&file, NewJSONFormatter())),
+		WithSink(NewSink(&errs, nil, WithSinkLevel(logrus.ErrorLevel, logrus.ErrorLevel))),
+	)
+
+	logger.Debug("[DB] query")
+	logger.Info("[HTTP] request")
+	logger.Error("[DB] failed")
+
+	if out := console.String(); strings.Contains(out, "query") || !strings.Contains(out, "request") || !strings.Contains(out, "failed") {
+		t.Errorf("Expected Info and above on the console sink, got %q", out)
+	}
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	var msgs []string
+	for _, line := range lines {
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			t.Fatalf("Expected JSON lines in the file sink, got %q", line)
+		}
+		msgs = append(msgs, obj["msg"].(string))
+	}
+	// The first line is the logger's own init message at debug level
+	if strings.Join(msgs[len(msgs)-3:], ",") != "query,request,failed" {
+		t.Errorf("Expected every level in the JSON sink, got %v", msgs)
+	}
+
+	if out := errs.String(); strings.Count(out, "\n") != 1 || !strings.Contains(out, "ERROR") || !strings.Contains(out, "failed") {
+		t.Errorf("Expected only the error in the error sink, got %q", out)
+	}
+}
+
+func TestSink_OwnLevelBelowLogger(t *testing.T) {
+	var console, file bytes.Buffer
+	logger := New(
+		WithLevel(logrus.InfoLevel),
+		WithoutCaller(),
+		WithSink(NewSink(&console, NewCustomFormatter(WithColors(false)))),
+		WithSink(NewSink(&file, NewCustomFormatter(WithColors(false)), WithSinkLevel(logrus.DebugLevel, logrus.PanicLevel))),
+	)
+	if GetLevel(logger) != logrus.InfoLevel || logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected level info behind a debug gate, got %v and %v", GetLevel(logger), logger.GetLevel())
+	}
+
+	logger.Debug("[DB] query")
+	logger.Info("[HTTP] request")
+	if out := console.String(); strings.Contains(out, "query") || !strings.Contains(out, "request") {
+		t.Errorf("Expected the console sink to follow the logger level, got %q", out)
+	}
+	if out := file.String(); !strings.Contains(out, "query") || !strings.Contains(out, "request") {
+		t.Errorf("Expected debug entries in the sink with its own level, got %q", out)
+	}
+
+	SetLevel(logger, logrus.WarnLevel)
+	logger.Info("[HTTP] later")
+	if strings.Contains(console.String(), "later") || !strings.Contains(file.String(), "later") {
+		t.Errorf("Expected only the sink with its own level to keep info entries, got %q and %q", console.String(), file.String())
+	}
+}
+
+func TestSink_Filter(t *testing.T) {
+	var buf bytes.Buffer
+	audit := NewSink(&buf, NewCustomFormatter(WithColors(false)), WithSinkFilter(func(e *logrus.Entry) bool {
+		return e.Data["audit"] == true
+	}))
+	logger := New(WithSink(audit), WithoutCaller())
+
+	logger.Info("ignored")
+	logger.WithField("audit", true).Info("user deleted")
+
+	if out := buf.String(); strings.Contains(out, "ignored") || !strings.Contains(out, "user deleted") {
+		t.Errorf("Expected only audit entries, got %q", out)
+	}
+}
+
+func TestSink_DefaultFormatter(t *testing.T) {
+	mw := NewMultiWriter(MultiWriterWithFormattersConfig{format: FormatPlain})
+	mw.AddSink(NewSink(&bytes.Buffer{}, nil))
+
+	f, ok := mw.pairs[0].f.(*CustomFormatter)
+	if !ok {
+		t.Fatalf("Expected a CustomFormatter, got %T", mw.pairs[0].f)
+	}
+	if !f.ShowTimestamp || f.UseColors {
+		t.Errorf("Expected timestamps and no colors for a buffer, got timestamp=%v colors=%v", f.ShowTimestamp, f.UseColors)
+	}
+}
+
+func TestSink_ReplacesOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(WithOutput(OutputConsole), WithSink(NewSink(&buf, nil)))
+	if logger.Out == nil || logger.Out == &buf {
+		t.Fatalf("Expected the logger output to be replaced by the hook")
+	}
+	logger.Info("hello")
+	if !strings.Contains(buf.String(), "hello") {
+		t.Errorf("Expected the entry in the sink, got %q", buf.String())
+	}
+}
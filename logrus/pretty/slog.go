@@ -0,0 +1,173 @@
+package pretty
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SlogHandler is a log/slog Handler that renders records through a CustomFormatter,
+// so slog and logrus loggers produce identical output.
+//
+// Attributes become fields (sorted like any other logrus field) and groups are
+// flattened into dotted keys, e.g. slog.Group("req", "id", 7) renders as req.id=7.
+type SlogHandler struct {
+	w      io.Writer
+	f      *CustomFormatter
+	opts   slog.HandlerOptions
+	attrs  logrus.Fields // Attributes added through WithAttrs, already prefixed
+	groups []string      // Open groups from WithGroup, outermost first
+	prefix string        // Dotted form of groups, e.g. "req.headers."
+	mu     *sync.Mutex   // Shared by all handlers derived from the same root
+}
+
+// NewSlogHandler creates a handler writing to w with the given formatter.
+// A nil formatter uses NewCustomFormatter() defaults and nil opts log at Info and above.
+//
+// Caller info is taken from the record's PC and shown according to the
+// formatter's ShowCaller and CallerLevel, so opts.AddSource is not needed.
+func NewSlogHandler(w io.Writer, f *CustomFormatter, opts *slog.HandlerOptions) *SlogHandler {
+	if f == nil {
+		f = NewCustomFormatter()
+	}
+	h := &SlogHandler{
+		w:     w,
+		f:     f,
+		attrs: logrus.Fields{},
+		mu:    &sync.Mutex{},
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// Enabled reports whether the handler emits records at the given level
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+// Handle formats the record with the CustomFormatter and writes it out
+func (h *SlogHandler) Handle(_ context.Context, r slog.Record) error {
+	data := make(logrus.Fields, len(h.attrs)+r.NumAttrs())
+	for k, v := range h.attrs {
+		data[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		h.addAttr(data, h.prefix, h.groups, a)
+		return true
+	})
+
+	entry := &logrus.Entry{
+		Data:    data,
+		Time:    r.Time,
+		Level:   slogToLogrusLevel(r.Level),
+		Message: r.Message,
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now() // Records built without a time, e.g. slog.Record{}
+	}
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		entry.Caller = &frame
+	}
+
+	buf, err := h.f.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err = h.w.Write(buf)
+	return err
+}
+
+// WithAttrs returns a handler that adds attrs to every record
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := h.clone()
+	for _, a := range attrs {
+		h2.addAttr(h2.attrs, h2.prefix, h2.groups, a)
+	}
+	return h2
+}
+
+// WithGroup returns a handler that nests all following attributes under name
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.groups = append(h2.groups, name)
+	h2.prefix += name + "."
+	return h2
+}
+
+func (h *SlogHandler) clone() *SlogHandler {
+	h2 := *h
+	h2.attrs = make(logrus.Fields, len(h.attrs))
+	for k, v := range h.attrs {
+		h2.attrs[k] = v
+	}
+	h2.groups = append([]string(nil), h.groups...)
+	return &h2
+}
+
+// addAttr resolves a and stores it in data, flattening groups into dotted keys
+func (h *SlogHandler) addAttr(data logrus.Fields, prefix string, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		members := a.Value.Group()
+		if len(members) == 0 {
+			return
+		}
+		// Groups with an empty key are inlined into the current level
+		if a.Key != "" {
+			prefix += a.Key + "."
+			groups = append(groups[:len(groups):len(groups)], a.Key)
+		}
+		for _, m := range members {
+			h.addAttr(data, prefix, groups, m)
+		}
+		return
+	}
+
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	data[prefix+a.Key] = a.Value.Any()
+}
+
+// slogToLogrusLevel maps slog levels onto the closest logrus level.
+// Levels below Debug become Trace, and anything above Error stays Error.
+func slogToLogrusLevel(l slog.Level) logrus.Level {
+	switch {
+	case l >= slog.LevelError:
+		return logrus.ErrorLevel
+	case l >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case l >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case l >= slog.LevelDebug:
+		return logrus.DebugLevel
+	default:
+		return logrus.TraceLevel
+	}
+}
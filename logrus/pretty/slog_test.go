@@ -0,0 +1,179 @@
+package pretty
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSlogHandler_MatchesLogrusOutput(t *testing.T) {
+	f := &CustomFormatter{UseColors: false, CallerLevel: logrus.WarnLevel, BracketPadding: 15}
+
+	var slogBuf bytes.Buffer
+	logger := slog.New(NewSlogHandler(&slogBuf, f, nil))
+	logger.Info("[Server] Started", "port", 8080, "env", "dev")
+
+	var logrusBuf bytes.Buffer
+	l := logrus.New()
+	l.SetOutput(&logrusBuf)
+	l.SetFormatter(f)
+	l.WithFields(logrus.Fields{"port": 8080, "env": "dev"}).Info("[Server] Started")
+
+	if slogBuf.String() != logrusBuf.String() {
+		t.Errorf("Expected identical output\nslog:   %q\nlogrus: %q", slogBuf.String(), logrusBuf.String())
+	}
+}
+
+func TestSlogHandler_Levels(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected string
+	}{
+		{slog.LevelDebug - 4, "TRACE"},
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo, "INFO"},
+		{slog.LevelWarn, "WARN"},
+		{slog.LevelError, "ERROR"},
+		{slog.LevelError + 4, "ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := NewSlogHandler(&buf, &CustomFormatter{}, &slog.HandlerOptions{Level: slog.LevelDebug - 4})
+			slog.New(h).Log(context.Background(), tt.level, "message")
+
+			if !strings.HasPrefix(buf.String(), tt.expected) {
+				t.Errorf("Expected level %s, got: %s", tt.expected, buf.String())
+			}
+		})
+	}
+}
+
+func TestSlogHandler_Enabled(t *testing.T) {
+	h := NewSlogHandler(&bytes.Buffer{}, nil, nil)
+	if h.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("Expected Debug to be disabled by default")
+	}
+	if !h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected Info to be enabled by default")
+	}
+
+	h = NewSlogHandler(&bytes.Buffer{}, nil, &slog.HandlerOptions{Level: slog.LevelWarn})
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Expected Info to be disabled with Warn minimum")
+	}
+}
+
+func TestSlogHandler_WithAttrsAndGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(&buf, &CustomFormatter{}, nil))
+
+	logger.With("service", "api").
+		WithGroup("req").
+		With("id", 7).
+		Info("[HTTP] Handled", slog.Group("headers", "accept", "json"), "status", 200)
+
+	output := buf.String()
+	for _, want := range []string{"service=api", "req.id=7", "req.headers.accept=json", "req.status=200"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+}
+
+func TestSlogHandler_SortedAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(&buf, &CustomFormatter{}, nil))
+	logger.Info("msg", "zebra", 1, "alpha", 2, "mid", 3)
+
+	output := buf.String()
+	a, m, z := strings.Index(output, "alpha="), strings.Index(output, "mid="), strings.Index(output, "zebra=")
+	if a > m || m > z {
+		t.Errorf("Expected attrs sorted alphabetically, got: %s", output)
+	}
+}
+
+func TestSlogHandler_EmptyGroupsAndAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewSlogHandler(&buf, &CustomFormatter{}, nil))
+	logger.WithGroup("").Info("msg", slog.Group("empty"), slog.Group("", "inline", true))
+
+	output := buf.String()
+	if strings.Contains(output, "empty") {
+		t.Errorf("Expected empty group to be dropped, got: %s", output)
+	}
+	if !strings.Contains(output, "inline=true") {
+		t.Errorf("Expected unnamed group to be inlined, got: %s", output)
+	}
+}
+
+func TestSlogHandler_ReplaceAttr(t *testing.T) {
+	var buf bytes.Buffer
+	opts := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == "password" {
+				return slog.Attr{}
+			}
+			if a.Key == "id" && len(groups) == 1 && groups[0] == "user" {
+				return slog.String("id", "redacted")
+			}
+			return a
+		},
+	}
+	logger := slog.New(NewSlogHandler(&buf, &CustomFormatter{}, opts))
+	logger.Info("login", "password", "hunter2", slog.Group("user", "id", 42))
+
+	output := buf.String()
+	if strings.Contains(output, "hunter2") {
+		t.Errorf("Expected dropped attr to be removed, got: %s", output)
+	}
+	if !strings.Contains(output, "user.id=redacted") {
+		t.Errorf("Expected replaced grouped attr, got: %s", output)
+	}
+}
+
+func TestSlogHandler_Caller(t *testing.T) {
+	var buf bytes.Buffer
+	f := &CustomFormatter{ShowCaller: true, CallerLevel: logrus.WarnLevel, UseRelativePath: true}
+	logger := slog.New(NewSlogHandler(&buf, f, nil))
+
+	logger.Info("no caller")
+	if strings.Contains(buf.String(), "at (") {
+		t.Errorf("Expected no caller info below CallerLevel, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	logger.Error("with caller")
+	if !strings.Contains(buf.String(), "slog_test.go:") {
+		t.Errorf("Expected caller info pointing at the test file, got: %s", buf.String())
+	}
+}
+
+func TestSlogHandler_Timestamp(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(&buf, &CustomFormatter{ShowTimestamp: true}, nil)
+
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.Local)
+	r := slog.NewRecord(ts, slog.LevelInfo, "stamped", 0)
+	if err := h.Handle(context.Background(), r); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "[2024-03-01 12:30:00]") {
+		t.Errorf("Expected record time in output, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Time{}, slog.LevelInfo, "unstamped", 0)); err != nil {
+		t.Fatalf("Handle failed: %v", err)
+	}
+	if out := buf.String(); strings.HasPrefix(out, "[0001-01-01") {
+		t.Errorf("Expected the current time for a zero record time, got: %s", out)
+	}
+}
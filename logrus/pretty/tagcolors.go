@@ -0,0 +1,323 @@
+package pretty
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// TagColorMode defines how bracketed tags are colored when ColorBrackets is on
+type TagColorMode int
+
+const (
+	TagColorFixed     TagColorMode = iota // Every tag in the theme's tag color, ColorYellow by default
+	TagColor256                           // A stable 256-color per tag, picked by hash
+	TagColorTrueColor                     // A stable 24-bit color per tag, picked by hash
+)
+
+// WithTagColors sets explicit tag colors, overriding the hashed ones. Tags are
+// matched case-insensitively. Values can be a color name ("cyan", "bright-red"),
+// a 256-color index ("208"), a hex color ("#ff8700") or a raw ANSI escape.
+// Hex colors and indexes are brought down to ColorDepth when it is set.
+// Invalid values fall back to the automatic color.
+func WithTagColors(colors map[string]string) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.TagColors = colors
+	}
+}
+
+// WithTagColorMode picks how tags without an explicit color are colored
+func WithTagColorMode(mode TagColorMode) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.TagColorMode = mode
+	}
+}
+
+// tagColor returns the escape sequence used for a tag
+func (f *CustomFormatter) tagColor(tag string) string {
+	if code, ok := f.tagColorOverride(tag); ok {
+		return code
+	}
+	mode := f.TagColorMode
+	if f.ColorDepth != 0 {
+		// Step down to what the terminal can show
+		if mode == TagColorTrueColor && f.ColorDepth < ColorDepthTrue {
+			mode = TagColor256
+		}
+		if mode == TagColor256 && f.ColorDepth < ColorDepth256 {
+			mode = TagColorFixed
+		}
+	}
+	switch mode {
+	case TagColor256, TagColorTrueColor:
+		return hashedTagColor(mode, tag)
+	default:
+		return f.palette().tag
+	}
+}
+
+func (f *CustomFormatter) tagColorOverride(tag string) (string, bool) {
+	if len(f.TagColors) == 0 {
+		return "", false
+	}
+	value, ok := f.TagColors[tag]
+	if !ok {
+		for k, v := range f.TagColors {
+			if strings.EqualFold(k, tag) {
+				value, ok = v, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", false
+	}
+	code, err := parseColor(value, f.ColorDepth)
+	return code, err == nil
+}
+
+var colorNames = map[string]string{
+	"black":          "\033[30m",
+	"red":            ColorRed,
+	"green":          ColorGreen,
+	"yellow":         ColorYellow,
+	"blue":           "\033[34m",
+	"magenta":        ColorMagenta,
+	"cyan":           ColorCyan,
+	"white":          "\033[37m",
+	"gray":           ColorGray,
+	"grey":           ColorGray,
+	"bright-red":     "\033[91m",
+	"bright-green":   "\033[92m",
+	"bright-yellow":  "\033[93m",
+	"bright-blue":    "\033[94m",
+	"bright-magenta": "\033[95m",
+	"bright-cyan":    "\033[96m",
+	"bright-white":   "\033[97m",
+}
+
+// ParseColor converts a color name, 256-color index, "#rrggbb" hex color or raw
+// ANSI escape into an escape sequence
+func ParseColor(s string) (string, error) {
+	return parseColor(s, 0)
+}
+
+// parseColor is ParseColor for a terminal of the given depth: hex colors and
+// 256-color indexes it cannot show become the nearest color it can. Depth 0
+// keeps every color as given.
+func parseColor(s string, depth ColorDepth) (string, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "\033["):
+		return s, nil
+	case strings.HasPrefix(s, "#"):
+		if len(s) != 7 {
+			return "", fmt.Errorf("invalid hex color %q (want #rrggbb)", s)
+		}
+		rgb, err := strconv.ParseUint(s[1:], 16, 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid hex color %q (want #rrggbb)", s)
+		}
+		r, g, b := uint8(rgb>>16), uint8(rgb>>8), uint8(rgb)
+		switch {
+		case depth == 0 || depth >= ColorDepthTrue:
+			return trueColor(r, g, b), nil
+		case depth >= ColorDepth256:
+			return color256(nearest256(r, g, b)), nil
+		default:
+			return nearestBasic(r, g, b), nil
+		}
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > 255 {
+			return "", fmt.Errorf("color index %d out of range 0-255", n)
+		}
+		if depth != 0 && depth < ColorDepth256 {
+			return nearestBasic(rgb256(n)), nil
+		}
+		return color256(n), nil
+	}
+	if code, ok := colorNames[strings.ToLower(s)]; ok {
+		return code, nil
+	}
+	return "", fmt.Errorf("unknown color %q", s)
+}
+
+func color256(n int) string          { return fmt.Sprintf("\033[38;5;%dm", n) }
+func trueColor(r, g, b uint8) string { return fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b) }
+
+// basicRGB are the xterm values of the 16 basic colors, in escape code order
+// 30-37 then 90-97
+var basicRGB = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// cubeLevels are the channel values of the 6x6x6 xterm color cube
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// rgb256 returns the xterm value of a 256-color index
+func rgb256(n int) (r, g, b uint8) {
+	switch {
+	case n < 16:
+		c := basicRGB[n]
+		return c[0], c[1], c[2]
+	case n < 232:
+		n -= 16
+		return cubeLevels[n/36], cubeLevels[n/6%6], cubeLevels[n%6]
+	default:
+		v := uint8(8 + 10*(n-232))
+		return v, v, v
+	}
+}
+
+// nearest256 returns the cube or gray ramp index closest to the color
+func nearest256(r, g, b uint8) int {
+	best, bestDist := 0, -1
+	for n := 16; n < 256; n++ {
+		r2, g2, b2 := rgb256(n)
+		if d := colorDistance(r, g, b, r2, g2, b2); bestDist < 0 || d < bestDist {
+			best, bestDist = n, d
+		}
+	}
+	return best
+}
+
+// nearestBasic returns the escape of the basic color closest to the color
+func nearestBasic(r, g, b uint8) string {
+	best, bestDist := 0, -1
+	for i, c := range basicRGB {
+		if d := colorDistance(r, g, b, c[0], c[1], c[2]); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	if best < 8 {
+		return fmt.Sprintf("\033[%dm", 30+best)
+	}
+	return fmt.Sprintf("\033[%dm", 90+best-8)
+}
+
+// colorDistance returns the squared distance between two RGB colors
+func colorDistance(r, g, b, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r)-int(r2), int(g)-int(g2), int(b)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
+
+type tagColorKey struct {
+	mode TagColorMode
+	tag  string
+}
+
+// tagColorCacheSize bounds tagColorCache, since tags come from log messages
+// and are not a fixed set. Tags beyond it are hashed on every use.
+const tagColorCacheSize = 1024
+
+// tagColorCache keeps hashed colors so common tags are only computed once
+var tagColorCache struct {
+	sync.Map              // tagColorKey -> string
+	size     atomic.Int32 // Entries stored
+}
+
+// hashedTagColor picks a stable color for tag. The same tag always gets the same
+// color, whatever its case, formatter or process.
+func hashedTagColor(mode TagColorMode, tag string) string {
+	key := tagColorKey{mode, strings.ToLower(tag)}
+	if code, ok := tagColorCache.Load(key); ok {
+		return code.(string)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key.tag))
+	sum := h.Sum32()
+
+	var code string
+	if mode == TagColorTrueColor {
+		code = trueColor(hueColor(float64(sum % 360)))
+	} else {
+		code = color256(tagPalette256[sum%uint32(len(tagPalette256))])
+	}
+	if tagColorCache.size.Load() < tagColorCacheSize {
+		if _, loaded := tagColorCache.LoadOrStore(key, code); !loaded {
+			tagColorCache.size.Add(1)
+		}
+	}
+	return code
+}
+
+// tagPalette256 holds the colors of the 6x6x6 xterm cube that are readable on
+// both dark and light backgrounds, leaving out grays
+var tagPalette256 = func() []int {
+	var palette []int
+	for i := 0; i < 216; i++ {
+		r, g, b := cubeLevels[i/36], cubeLevels[i/6%6], cubeLevels[i%6]
+		if max(r, g, b)-min(r, g, b) >= 80 && readableOnAnyBackground(r, g, b) {
+			palette = append(palette, 16+i)
+		}
+	}
+	return palette
+}()
+
+// hueColor returns a saturated color of the given hue whose lightness is tuned
+// to stay readable on dark and light backgrounds
+func hueColor(hue float64) (r, g, b uint8) {
+	lo, hi := 0.2, 0.8
+	for i := 0; i < 20; i++ {
+		l := (lo + hi) / 2
+		r, g, b = hslToRGB(hue, 0.75, l)
+		if luminance(r, g, b) < 0.2 {
+			lo = l
+		} else {
+			hi = l
+		}
+	}
+	return r, g, b
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	c := (1 - math.Abs(2*l-1)) * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := l - c/2
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g = c, x
+	case h < 120:
+		r, g = x, c
+	case h < 180:
+		g, b = c, x
+	case h < 240:
+		g, b = x, c
+	case h < 300:
+		r, b = x, c
+	default:
+		r, b = c, x
+	}
+	to8 := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return to8(r), to8(g), to8(b)
+}
+
+// luminance is the WCAG relative luminance of an sRGB color
+func luminance(r, g, b uint8) float64 {
+	lin := func(c uint8) float64 {
+		v := float64(c) / 255
+		if v <= 0.03928 {
+			return v / 12.92
+		}
+		return math.Pow((v+0.055)/1.055, 2.4)
+	}
+	return 0.2126*lin(r) + 0.7152*lin(g) + 0.0722*lin(b)
+}
+
+// readableOnAnyBackground reports whether the color reaches a WCAG contrast
+// ratio of 3:1 against both black and white
+func readableOnAnyBackground(r, g, b uint8) bool {
+	l := luminance(r, g, b)
+	onBlack := (l + 0.05) / 0.05
+	onWhite := 1.05 / (l + 0.05)
+	return onBlack >= 3 && onWhite >= 3
+}
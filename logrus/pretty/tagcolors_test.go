@@ -0,0 +1,176 @@
+package pretty
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestTagColor_FixedByDefault(t *testing.T) {
+	f := NewCustomFormatter()
+	if got := f.tagColor("DB"); got != ColorYellow {
+		t.Errorf("Expected yellow by default, got %q", got)
+	}
+}
+
+func TestTagColor_HashedIsStable(t *testing.T) {
+	for _, mode := range []TagColorMode{TagColor256, TagColorTrueColor} {
+		f := NewCustomFormatter(WithTagColorMode(mode))
+		g := NewCustomFormatter(WithTagColorMode(mode))
+
+		if f.tagColor("Auth") != g.tagColor("auth") {
+			t.Errorf("Mode %d: expected the same color across formatters and case", mode)
+		}
+
+		seen := map[string]bool{}
+		for _, tag := range []string{"Auth", "DB", "HTTP", "Cache", "Queue", "Server"} {
+			seen[f.tagColor(tag)] = true
+		}
+		if len(seen) < 3 {
+			t.Errorf("Mode %d: expected tags to spread over colors, got %d distinct", mode, len(seen))
+		}
+	}
+}
+
+func TestTagColor_256Format(t *testing.T) {
+	f := NewCustomFormatter(WithTagColorMode(TagColor256))
+	if got := f.tagColor("DB"); !strings.HasPrefix(got, "\033[38;5;") {
+		t.Errorf("Expected 256-color escape, got %q", got)
+	}
+	f.TagColorMode = TagColorTrueColor
+	if got := f.tagColor("DB"); !strings.HasPrefix(got, "\033[38;2;") {
+		t.Errorf("Expected truecolor escape, got %q", got)
+	}
+}
+
+func TestTagColor_StepsDownToColorDepth(t *testing.T) {
+	f := NewCustomFormatter(WithTagColorMode(TagColorTrueColor), WithColorDepth(ColorDepth256))
+	if got := f.tagColor("DB"); got != hashedTagColor(TagColor256, "DB") {
+		t.Errorf("Expected 256-color tag on a 256-color terminal, got %q", got)
+	}
+	f.ColorDepth = ColorDepth16
+	if got := f.tagColor("DB"); got != ColorYellow {
+		t.Errorf("Expected the theme tag color on a 16-color terminal, got %q", got)
+	}
+}
+
+func TestTagColor_ReadableOnDarkAndLight(t *testing.T) {
+	levels := [6]uint8{0, 95, 135, 175, 215, 255}
+	if len(tagPalette256) < 16 {
+		t.Fatalf("Expected a reasonably large palette, got %d colors", len(tagPalette256))
+	}
+	for _, idx := range tagPalette256 {
+		i := idx - 16
+		if !readableOnAnyBackground(levels[i/36], levels[i/6%6], levels[i%6]) {
+			t.Errorf("Palette color %d is not readable on both backgrounds", idx)
+		}
+	}
+	for hue := 0; hue < 360; hue += 15 {
+		r, g, b := hueColor(float64(hue))
+		if !readableOnAnyBackground(r, g, b) {
+			t.Errorf("Truecolor for hue %d (%d,%d,%d) is not readable on both backgrounds", hue, r, g, b)
+		}
+	}
+}
+
+func TestTagColor_Overrides(t *testing.T) {
+	f := NewCustomFormatter(WithTagColorMode(TagColor256), WithTagColors(map[string]string{
+		"db":    "cyan",
+		"HTTP":  "#ff8700",
+		"Cache": "208",
+		"Bad":   "not-a-color",
+	}))
+
+	tests := map[string]string{
+		"DB":    ColorCyan,
+		"HTTP":  "\033[38;2;255;135;0m",
+		"Cache": "\033[38;5;208m",
+		"Bad":   hashedTagColor(TagColor256, "Bad"),
+	}
+	for tag, want := range tests {
+		if got := f.tagColor(tag); got != want {
+			t.Errorf("tagColor(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestTagColor_OverridesFollowColorDepth(t *testing.T) {
+	f := NewCustomFormatter(WithColorDepth(ColorDepth16), WithTagColors(map[string]string{
+		"HTTP":  "#ff8700",
+		"Cache": "208",
+	}))
+	for _, tag := range []string{"HTTP", "Cache"} {
+		if got := f.tagColor(tag); got != ColorYellow {
+			t.Errorf("tagColor(%q) = %q, want yellow at 16 colors", tag, got)
+		}
+	}
+	if got := (&CustomFormatter{ColorDepth: ColorDepth256, TagColors: map[string]string{"HTTP": "#ff8700"}}).tagColor("HTTP"); got != "\033[38;5;208m" {
+		t.Errorf("Expected the nearest 256-color, got %q", got)
+	}
+}
+
+func TestTagColor_CacheBounded(t *testing.T) {
+	for i := range tagColorCacheSize + 100 {
+		hashedTagColor(TagColor256, "tag-"+strconv.Itoa(i))
+	}
+	n := 0
+	tagColorCache.Range(func(any, any) bool { n++; return true })
+	if n > tagColorCacheSize {
+		t.Errorf("Expected at most %d cached tags, got %d", tagColorCacheSize, n)
+	}
+	if got := hashedTagColor(TagColor256, "tag-uncached"); got != hashedTagColor(TagColor256, "TAG-UNCACHED") || got == "" {
+		t.Errorf("Expected tags beyond the cache to still get a stable color, got %q", got)
+	}
+}
+
+func TestTagColor_AllStyles(t *testing.T) {
+	for _, style := range []TagStyle{StyleDefault, StyleCenter, StyleRight} {
+		f := NewCustomFormatter(WithTagStyle(style, ""), WithTagColors(map[string]string{"DB": "cyan"}))
+		out := formatEntry(t, f, logrus.InfoLevel, "[DB] query", nil)
+		if !strings.Contains(out, ColorCyan+"[") {
+			t.Errorf("Style %d: expected the tag color on the bracket, got %q", style, out)
+		}
+		if strings.Contains(out, ColorYellow+"[") {
+			t.Errorf("Style %d: expected no default yellow, got %q", style, out)
+		}
+	}
+}
+
+func TestTagColor_JoinedPathUsesFirstTag(t *testing.T) {
+	f := NewCustomFormatter(WithTagPath(TagPathJoined, ""), WithTagColors(map[string]string{"API": "green"}))
+	out := formatEntry(t, f, logrus.InfoLevel, "[API][Users] created", nil)
+	if !strings.Contains(out, ColorGreen+"[API›Users]") {
+		t.Errorf("Expected joined path in the first tag's color, got %q", out)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"red", ColorRed, false},
+		{"Bright-Blue", "\033[94m", false},
+		{"33", "\033[38;5;33m", false},
+		{"#00ff00", "\033[38;2;0;255;0m", false},
+		{"\033[1;31m", "\033[1;31m", false},
+		{"256", "", true},
+		{"#fff", "", true},
+		{"purple-ish", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.in), func(t *testing.T) {
+			got, err := ParseColor(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseColor(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package pretty
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TagLevelFallback is the tag-levels key that sets the level for untagged
+// messages and tags without their own entry
+const TagLevelFallback = "*"
+
+// ParseTagLevels parses the LOG_TAG_LEVELS form "DB=debug,HTTP=warn,*=info".
+// Tags are matched case-insensitively.
+func ParseTagLevels(s string) (map[string]logrus.Level, error) {
+	levels := map[string]logrus.Level{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		tag, name, ok := strings.Cut(pair, "=")
+		tag, name = strings.TrimSpace(tag), strings.TrimSpace(name)
+		if !ok || tag == "" {
+			return nil, fmt.Errorf("invalid tag level %q (want TAG=level)", pair)
+		}
+		lvl, err := logrus.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag level %q: unknown level %q", pair, name)
+		}
+		levels[tag] = lvl
+	}
+	return levels, nil
+}
+
+// tagFilter drops entries whose bracket tag is configured for a less verbose level.
+// Other entries are held to the level of the logger that created them.
+type tagFilter struct {
+	levels   map[string]logrus.Level // Keys are lower-cased tags
+	fallback logrus.Level            // For entries of loggers not created by New or Child
+}
+
+// newTagFilter builds a filter from user levels. Without a "*" entry, untagged
+// messages use fallback, normally the logger's configured level. apply makes
+// fallback the logger's level, so SetLevel changes it later.
+func newTagFilter(levels map[string]logrus.Level, fallback logrus.Level) *tagFilter {
+	f := &tagFilter{levels: make(map[string]logrus.Level, len(levels)), fallback: fallback}
+	for tag, lvl := range levels {
+		if tag == TagLevelFallback {
+			f.fallback = lvl
+			continue
+		}
+		f.levels[strings.ToLower(tag)] = lvl
+	}
+	return f
+}
+
+func (f *tagFilter) allows(e *logrus.Entry) bool {
+	limit := f.fallback
+	if m := metaOf(e); m != nil {
+		limit = m.level
+	}
+	if lvl, ok := f.tagLevel(e); ok {
+		limit = lvl
+	}
+	return e.Level <= limit
+}
+
+// tagLevel returns the level set for the tag of e, if any
+func (f *tagFilter) tagLevel(e *logrus.Entry) (logrus.Level, bool) {
+	if len(f.levels) == 0 {
+		return 0, false
+	}
+	tag, loc := findTag(e.Message)
+	if loc == nil {
+		return 0, false
+	}
+	lvl, ok := f.levels[strings.ToLower(tag)]
+	return lvl, ok
+}
+
+// mostVerbose is the level the logger itself must allow so every tag can reach the filter
+func (f *tagFilter) mostVerbose() logrus.Level {
+	lvl := logrus.PanicLevel
+	for _, l := range f.levels {
+		lvl = max(lvl, l)
+	}
+	return lvl
+}
+
+// filteredFormatter skips formatting for entries rejected by its filter.
+// logrus writes the empty result, so rejected entries never reach the output.
+type filteredFormatter struct {
+	logrus.Formatter
+	filter *tagFilter
+}
+
+func (f *filteredFormatter) Format(e *logrus.Entry) ([]byte, error) {
+	if !f.filter.allows(e) {
+		return nil, nil
+	}
+	return f.Formatter.Format(e)
+}
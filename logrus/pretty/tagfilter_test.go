@@ -0,0 +1,170 @@
+package pretty
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestParseTagLevels(t *testing.T) {
+	levels, err := ParseTagLevels(" DB=debug, HTTP = warn ,*=error,")
+	if err != nil {
+		t.Fatalf("ParseTagLevels failed: %v", err)
+	}
+
+	expected := map[string]logrus.Level{"DB": logrus.DebugLevel, "HTTP": logrus.WarnLevel, "*": logrus.ErrorLevel}
+	if len(levels) != len(expected) {
+		t.Fatalf("Expected %d levels, got %v", len(expected), levels)
+	}
+	for tag, lvl := range expected {
+		if levels[tag] != lvl {
+			t.Errorf("Expected %s=%v, got %v", tag, lvl, levels[tag])
+		}
+	}
+}
+
+func TestParseTagLevels_Invalid(t *testing.T) {
+	for _, input := range []string{"DB", "=debug", "DB=loud"} {
+		if _, err := ParseTagLevels(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+}
+
+func TestTagFilter_Allows(t *testing.T) {
+	filter := newTagFilter(map[string]logrus.Level{"DB": logrus.DebugLevel, "HTTP": logrus.WarnLevel}, logrus.InfoLevel)
+
+	tests := []struct {
+		message string
+		level   logrus.Level
+		allowed bool
+	}{
+		{"[DB] query", logrus.DebugLevel, true},
+		{"[db] query", logrus.DebugLevel, true},
+		{"[DB] query", logrus.TraceLevel, false},
+		{"[HTTP] request", logrus.InfoLevel, false},
+		{"[HTTP] failed", logrus.WarnLevel, true},
+		{"[Cache] miss", logrus.DebugLevel, false},
+		{"[Cache] miss", logrus.InfoLevel, true},
+		{"untagged", logrus.InfoLevel, true},
+		{"untagged", logrus.DebugLevel, false},
+	}
+
+	for _, tt := range tests {
+		e := &logrus.Entry{Message: tt.message, Level: tt.level}
+		if got := filter.allows(e); got != tt.allowed {
+			t.Errorf("allows(%q at %v) = %v, want %v", tt.message, tt.level, got, tt.allowed)
+		}
+	}
+
+	if filter.mostVerbose() != logrus.DebugLevel {
+		t.Errorf("Expected most verbose level debug, got %v", filter.mostVerbose())
+	}
+}
+
+func TestTagFilter_FallbackKey(t *testing.T) {
+	filter := newTagFilter(map[string]logrus.Level{"DB": logrus.DebugLevel, "*": logrus.ErrorLevel}, logrus.InfoLevel)
+
+	if filter.allows(&logrus.Entry{Message: "untagged", Level: logrus.WarnLevel}) {
+		t.Error("Expected \"*\" entry to override the logger level for untagged messages")
+	}
+	if !filter.allows(&logrus.Entry{Message: "[DB] q", Level: logrus.DebugLevel}) {
+		t.Error("Expected tagged entry to keep its own level")
+	}
+}
+
+func TestNew_WithTagLevels(t *testing.T) {
+	logger := New(
+		WithLevel(logrus.InfoLevel),
+		WithTagLevels(map[string]logrus.Level{"DB": logrus.DebugLevel, "HTTP": logrus.WarnLevel}),
+	)
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	if logger.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected logger level lowered to debug for the DB tag, got %v", logger.GetLevel())
+	}
+
+	logger.Debug("[DB] select 1")
+	logger.Info("[HTTP] GET /health")
+	logger.Warn("[HTTP] slow response")
+	logger.Debug("[Cache] miss")
+	logger.Info("plain info")
+
+	output := buf.String()
+	for _, want := range []string{"select 1", "slow response", "plain info"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in output, got: %s", want, output)
+		}
+	}
+	for _, unwanted := range []string{"GET /health", "miss"} {
+		if strings.Contains(output, unwanted) {
+			t.Errorf("Expected %q to be filtered, got: %s", unwanted, output)
+		}
+	}
+}
+
+func TestNew_TagLevelsFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("LOG_TAG_LEVELS", "DB=trace")
+
+	logger := New()
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+
+	logger.Trace("[DB] row")
+	logger.Debug("[API] hidden")
+
+	if !strings.Contains(buf.String(), "row") || strings.Contains(buf.String(), "hidden") {
+		t.Errorf("Expected LOG_TAG_LEVELS to drive filtering, got: %s", buf.String())
+	}
+}
+
+func TestNew_TagLevelsMultiOutput(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	logger := New(
+		WithOutput(OutputMulti),
+		WithFile(logFile),
+		WithTagLevels(map[string]logrus.Level{"Noisy": logrus.ErrorLevel}),
+	)
+
+	for _, h := range logger.Hooks[logrus.InfoLevel] {
+		if ch, ok := h.(*CustomHook); ok {
+			ch.mw.pairs = ch.mw.pairs[1:] // Keep only the file writer
+		}
+	}
+
+	logger.Info("[Noisy] chatter")
+	logger.Info("[Quiet] important")
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if strings.Contains(string(data), "chatter") || !strings.Contains(string(data), "important") {
+		t.Errorf("Expected multi output to apply tag levels, got: %s", data)
+	}
+}
+
+func TestLoadConfig_TagLevels(t *testing.T) {
+	fc, err := parseConfig("c.yaml", []byte("tag_levels:\n  DB: debug\n"))
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	opts, _ := fc.Options()
+	cfg := &Config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.TagLevels["DB"] != logrus.DebugLevel {
+		t.Errorf("Expected DB=debug from config file, got %v", cfg.TagLevels)
+	}
+
+	if _, err := parseConfig("c.yaml", []byte("tag_levels:\n  DB: loud\n")); err == nil || !strings.Contains(err.Error(), "c.yaml:2: tag_levels.DB: unknown level") {
+		t.Errorf("Expected precise error for bad tag level, got: %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+package pretty
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ColorDepth is the number of colors a terminal can show
+type ColorDepth int
+
+const (
+	ColorDepth16   ColorDepth = 16      // The basic ANSI colors only
+	ColorDepth256  ColorDepth = 256     // The xterm 256-color palette (default)
+	ColorDepthTrue ColorDepth = 1 << 24 // 24-bit truecolor
+)
+
+// ThemeColor is one theme color at each color depth. Values take anything
+// ParseColor accepts: names, 256-color indexes, "#rrggbb" or raw escapes.
+// An empty value falls back to the next lower depth; an empty Basic means no color.
+type ThemeColor struct {
+	Basic     string
+	Color256  string
+	TrueColor string
+}
+
+// Theme is the set of colors used by CustomFormatter
+type Theme struct {
+	Name string
+
+	Trace ThemeColor
+	Debug ThemeColor
+	Info  ThemeColor
+	Warn  ThemeColor
+	Error ThemeColor
+	Fatal ThemeColor
+	Panic ThemeColor
+
+	Tag        ThemeColor // Tags in TagColorFixed mode
+	TagPadding ThemeColor // Decoration of StyleCenter and StyleRight tags
+	Namespace  ThemeColor
+	FieldKey   ThemeColor
+	FieldValue ThemeColor // Strings and anything without a color of its own
+	Caller     ThemeColor
+
+	// Field values by type. Unset colors fall back to FieldValue.
+	FieldNumber ThemeColor
+	FieldBool   ThemeColor
+	FieldTime   ThemeColor // Times and durations
+	FieldNil    ThemeColor
+}
+
+// Built-in themes. ThemeDark is the default and matches the original colors.
+var (
+	ThemeDark = &Theme{
+		Name:       "dark",
+		Trace:      ThemeColor{Basic: ColorCyan},
+		Debug:      ThemeColor{Basic: ColorCyan},
+		Info:       ThemeColor{Basic: ColorGreen},
+		Warn:       ThemeColor{Basic: ColorYellow},
+		Error:      ThemeColor{Basic: ColorRed},
+		Fatal:      ThemeColor{Basic: ColorMagenta},
+		Panic:      ThemeColor{Basic: ColorMagenta},
+		Tag:        ThemeColor{Basic: ColorYellow},
+		TagPadding: ThemeColor{Basic: ColorGray, Color256: ColorVeryDimGray},
+		Namespace:  ThemeColor{Basic: ColorGray},
+		FieldKey:   ThemeColor{Basic: ColorGray, Color256: ColorVeryDimGray},
+		FieldValue: ThemeColor{Basic: ColorGray},
+		Caller:     ThemeColor{Basic: ColorGray, Color256: ColorVeryDimGray},
+
+		FieldNumber: ThemeColor{Basic: ColorCyan, Color256: "109"},
+		FieldBool:   ThemeColor{Basic: ColorMagenta, Color256: "139"},
+		FieldTime:   ThemeColor{Basic: "blue", Color256: "110"},
+		FieldNil:    ThemeColor{Basic: ColorGray, Color256: ColorDarkGray},
+	}
+
+	ThemeLight = &Theme{
+		Name:       "light",
+		Trace:      ThemeColor{Basic: "blue", Color256: "25", TrueColor: "#1f5fa8"},
+		Debug:      ThemeColor{Basic: "cyan", Color256: "31", TrueColor: "#007a8a"},
+		Info:       ThemeColor{Basic: "green", Color256: "28", TrueColor: "#1a7f37"},
+		Warn:       ThemeColor{Basic: "yellow", Color256: "130", TrueColor: "#9a6700"},
+		Error:      ThemeColor{Basic: "red", Color256: "160", TrueColor: "#cf222e"},
+		Fatal:      ThemeColor{Basic: "magenta", Color256: "125", TrueColor: "#a0235f"},
+		Panic:      ThemeColor{Basic: "magenta", Color256: "125", TrueColor: "#a0235f"},
+		Tag:        ThemeColor{Basic: "blue", Color256: "24", TrueColor: "#0550ae"},
+		TagPadding: ThemeColor{Basic: "gray", Color256: "248", TrueColor: "#a8a8a8"},
+		Namespace:  ThemeColor{Basic: "gray", Color256: "240", TrueColor: "#57606a"},
+		FieldKey:   ThemeColor{Basic: "gray", Color256: "244", TrueColor: "#8c959f"},
+		FieldValue: ThemeColor{Basic: "black", Color256: "238", TrueColor: "#424a53"},
+		Caller:     ThemeColor{Basic: "gray", Color256: "244", TrueColor: "#8c959f"},
+
+		FieldNumber: ThemeColor{Basic: "blue", Color256: "25", TrueColor: "#0550ae"},
+		FieldBool:   ThemeColor{Basic: "magenta", Color256: "91", TrueColor: "#8250df"},
+		FieldTime:   ThemeColor{Basic: "cyan", Color256: "30", TrueColor: "#1b7c83"},
+		FieldNil:    ThemeColor{Basic: "gray", Color256: "248", TrueColor: "#a8a8a8"},
+	}
+
+	ThemeSolarized = &Theme{
+		Name:       "solarized",
+		Trace:      ThemeColor{Basic: "blue", Color256: "61", TrueColor: "#6c71c4"},
+		Debug:      ThemeColor{Basic: "cyan", Color256: "37", TrueColor: "#2aa198"},
+		Info:       ThemeColor{Basic: "green", Color256: "64", TrueColor: "#859900"},
+		Warn:       ThemeColor{Basic: "yellow", Color256: "136", TrueColor: "#b58900"},
+		Error:      ThemeColor{Basic: "red", Color256: "160", TrueColor: "#dc322f"},
+		Fatal:      ThemeColor{Basic: "magenta", Color256: "125", TrueColor: "#d33682"},
+		Panic:      ThemeColor{Basic: "magenta", Color256: "125", TrueColor: "#d33682"},
+		Tag:        ThemeColor{Basic: "yellow", Color256: "166", TrueColor: "#cb4b16"},
+		TagPadding: ThemeColor{Basic: "gray", Color256: "240", TrueColor: "#586e75"},
+		Namespace:  ThemeColor{Basic: "blue", Color256: "33", TrueColor: "#268bd2"},
+		FieldKey:   ThemeColor{Basic: "gray", Color256: "240", TrueColor: "#586e75"},
+		FieldValue: ThemeColor{Basic: "gray", Color256: "244", TrueColor: "#839496"},
+		Caller:     ThemeColor{Basic: "gray", Color256: "240", TrueColor: "#586e75"},
+
+		FieldNumber: ThemeColor{Basic: "cyan", Color256: "37", TrueColor: "#2aa198"},
+		FieldBool:   ThemeColor{Basic: "magenta", Color256: "61", TrueColor: "#6c71c4"},
+		FieldTime:   ThemeColor{Basic: "blue", Color256: "33", TrueColor: "#268bd2"},
+		FieldNil:    ThemeColor{Basic: "gray", Color256: "240", TrueColor: "#586e75"},
+	}
+
+	ThemeHighContrast = &Theme{
+		Name:       "high-contrast",
+		Trace:      ThemeColor{Basic: "\033[1;94m"},
+		Debug:      ThemeColor{Basic: "\033[1;96m"},
+		Info:       ThemeColor{Basic: "\033[1;92m"},
+		Warn:       ThemeColor{Basic: "\033[1;93m"},
+		Error:      ThemeColor{Basic: "\033[1;91m"},
+		Fatal:      ThemeColor{Basic: "\033[1;97;41m"},
+		Panic:      ThemeColor{Basic: "\033[1;97;41m"},
+		Tag:        ThemeColor{Basic: "\033[1;93m"},
+		TagPadding: ThemeColor{Basic: "white"},
+		Namespace:  ThemeColor{Basic: "bright-white"},
+		FieldKey:   ThemeColor{Basic: "bright-cyan"},
+		FieldValue: ThemeColor{Basic: "bright-white"},
+		Caller:     ThemeColor{Basic: "white"},
+
+		FieldNumber: ThemeColor{Basic: "bright-yellow"},
+		FieldBool:   ThemeColor{Basic: "bright-magenta"},
+		FieldTime:   ThemeColor{Basic: "bright-blue"},
+		FieldNil:    ThemeColor{Basic: "white"},
+	}
+
+	// ThemeMonochrome uses no colors, only bold and dim to mark importance
+	ThemeMonochrome = &Theme{
+		Name:       "monochrome",
+		Warn:       ThemeColor{Basic: "\033[1m"},
+		Error:      ThemeColor{Basic: "\033[1m"},
+		Fatal:      ThemeColor{Basic: "\033[1;7m"},
+		Panic:      ThemeColor{Basic: "\033[1;7m"},
+		Tag:        ThemeColor{Basic: "\033[1m"},
+		TagPadding: ThemeColor{Basic: "\033[2m"},
+		FieldKey:   ThemeColor{Basic: "\033[2m"},
+		Caller:     ThemeColor{Basic: "\033[2m"},
+
+		FieldNil: ThemeColor{Basic: "\033[2m"},
+	}
+)
+
+var themes = struct {
+	sync.RWMutex
+	byName map[string]*Theme
+}{byName: map[string]*Theme{}}
+
+func init() {
+	for _, t := range []*Theme{ThemeDark, ThemeLight, ThemeSolarized, ThemeHighContrast, ThemeMonochrome} {
+		RegisterTheme(t)
+	}
+}
+
+// RegisterTheme makes a user-defined theme available by name to LookupTheme,
+// WithThemeName, config files and LOG_THEME. A theme with the same name is replaced.
+func RegisterTheme(t *Theme) {
+	themes.Lock()
+	defer themes.Unlock()
+	themes.byName[strings.ToLower(t.Name)] = t
+}
+
+// LookupTheme returns the theme registered under name, ignoring case
+func LookupTheme(name string) (*Theme, bool) {
+	themes.RLock()
+	defer themes.RUnlock()
+	t, ok := themes.byName[strings.ToLower(strings.TrimSpace(name))]
+	return t, ok
+}
+
+// ThemeNames lists the registered themes in sorted order
+func ThemeNames() []string {
+	themes.RLock()
+	defer themes.RUnlock()
+	names := make([]string, 0, len(themes.byName))
+	for name := range themes.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithTheme sets the formatter's colors
+func WithTheme(t *Theme) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.Theme = t
+	}
+}
+
+// WithColorDepth sets how many colors the formatter may use
+func WithColorDepth(depth ColorDepth) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.ColorDepth = depth
+	}
+}
+
+// themeRoles maps the role names used in config files to theme colors
+var themeRoles = map[string]func(t *Theme) *ThemeColor{
+	"trace":       func(t *Theme) *ThemeColor { return &t.Trace },
+	"debug":       func(t *Theme) *ThemeColor { return &t.Debug },
+	"info":        func(t *Theme) *ThemeColor { return &t.Info },
+	"warn":        func(t *Theme) *ThemeColor { return &t.Warn },
+	"error":       func(t *Theme) *ThemeColor { return &t.Error },
+	"fatal":       func(t *Theme) *ThemeColor { return &t.Fatal },
+	"panic":       func(t *Theme) *ThemeColor { return &t.Panic },
+	"tag":         func(t *Theme) *ThemeColor { return &t.Tag },
+	"tag_padding": func(t *Theme) *ThemeColor { return &t.TagPadding },
+	"namespace":   func(t *Theme) *ThemeColor { return &t.Namespace },
+	"field_key":   func(t *Theme) *ThemeColor { return &t.FieldKey },
+	"field_value": func(t *Theme) *ThemeColor { return &t.FieldValue },
+	"caller":      func(t *Theme) *ThemeColor { return &t.Caller },
+
+	"field_number": func(t *Theme) *ThemeColor { return &t.FieldNumber },
+	"field_bool":   func(t *Theme) *ThemeColor { return &t.FieldBool },
+	"field_time":   func(t *Theme) *ThemeColor { return &t.FieldTime },
+	"field_nil":    func(t *Theme) *ThemeColor { return &t.FieldNil },
+}
+
+func themeRoleNames() []string {
+	names := make([]string, 0, len(themeRoles))
+	for name := range themeRoles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// palette is a theme resolved to escape sequences for one color depth
+type palette struct {
+	levels     [7]string // Indexed by logrus.Level, Panic to Trace
+	tag        string
+	tagPadding string
+	namespace  string
+	fieldKey   string
+	fieldValue string
+	caller     string
+
+	fieldNumber string
+	fieldBool   string
+	fieldTime   string
+	fieldNil    string
+}
+
+// paletteKey holds the theme by value, so equal themes built again, e.g. by
+// every config file reload, share one palette
+type paletteKey struct {
+	theme Theme
+	depth ColorDepth
+}
+
+// paletteCache holds resolved palettes. Themes must not be changed once in use.
+var paletteCache sync.Map // paletteKey -> *palette
+
+// palette returns the resolved colors of the formatter's theme
+func (f *CustomFormatter) palette() *palette {
+	t := f.Theme
+	if t == nil {
+		t = ThemeDark
+	}
+	key := paletteKey{theme: *t, depth: f.ColorDepth}
+	if key.depth == 0 {
+		key.depth = ColorDepth256
+	}
+	if p, ok := paletteCache.Load(key); ok {
+		return p.(*palette)
+	}
+
+	d := key.depth
+	p := &palette{
+		tag:        t.Tag.resolve(d),
+		tagPadding: t.TagPadding.resolve(d),
+		namespace:  t.Namespace.resolve(d),
+		fieldKey:   t.FieldKey.resolve(d),
+		fieldValue: t.FieldValue.resolve(d),
+		caller:     t.Caller.resolve(d),
+	}
+	p.fieldNumber = t.FieldNumber.resolveOr(d, p.fieldValue)
+	p.fieldBool = t.FieldBool.resolveOr(d, p.fieldValue)
+	p.fieldTime = t.FieldTime.resolveOr(d, p.fieldValue)
+	p.fieldNil = t.FieldNil.resolveOr(d, p.fieldValue)
+	p.levels[logrus.PanicLevel] = t.Panic.resolve(d)
+	p.levels[logrus.FatalLevel] = t.Fatal.resolve(d)
+	p.levels[logrus.ErrorLevel] = t.Error.resolve(d)
+	p.levels[logrus.WarnLevel] = t.Warn.resolve(d)
+	p.levels[logrus.InfoLevel] = t.Info.resolve(d)
+	p.levels[logrus.DebugLevel] = t.Debug.resolve(d)
+	p.levels[logrus.TraceLevel] = t.Trace.resolve(d)
+
+	actual, _ := paletteCache.LoadOrStore(key, p)
+	return actual.(*palette)
+}
+
+func (p *palette) level(l logrus.Level) string {
+	if int(l) < len(p.levels) {
+		return p.levels[l]
+	}
+	return ColorReset
+}
+
+// resolve picks the deepest value the terminal supports, bringing hex colors
+// and 256-color indexes down to depth. Invalid values resolve to no color.
+func (c ThemeColor) resolve(depth ColorDepth) string {
+	value := c.Basic
+	if depth >= ColorDepth256 && c.Color256 != "" {
+		value = c.Color256
+	}
+	if depth >= ColorDepthTrue && c.TrueColor != "" {
+		value = c.TrueColor
+	}
+	if value == "" {
+		return ""
+	}
+	code, err := parseColor(value, depth)
+	if err != nil {
+		return ""
+	}
+	return code
+}
+
+// resolveOr resolves the color, or returns fallback when it is unset
+func (c ThemeColor) resolveOr(depth ColorDepth, fallback string) string {
+	if c == (ThemeColor{}) {
+		return fallback
+	}
+	return c.resolve(depth)
+}
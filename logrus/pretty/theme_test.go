@@ -0,0 +1,160 @@
+package pretty
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestTheme_DarkMatchesOriginalColors(t *testing.T) {
+	p := NewCustomFormatter().palette()
+
+	want := map[logrus.Level]string{
+		logrus.PanicLevel: ColorMagenta,
+		logrus.FatalLevel: ColorMagenta,
+		logrus.ErrorLevel: ColorRed,
+		logrus.WarnLevel:  ColorYellow,
+		logrus.InfoLevel:  ColorGreen,
+		logrus.DebugLevel: ColorCyan,
+		logrus.TraceLevel: ColorCyan,
+	}
+	for level, code := range want {
+		if got := p.level(level); got != code {
+			t.Errorf("Level %s: expected %q, got %q", level, code, got)
+		}
+	}
+	if p.fieldKey != ColorVeryDimGray || p.fieldValue != ColorGray || p.caller != ColorVeryDimGray || p.tag != ColorYellow {
+		t.Errorf("Unexpected default palette: %+v", p)
+	}
+}
+
+func TestTheme_ColorDepth(t *testing.T) {
+	c := ThemeColor{Basic: "green", Color256: "28", TrueColor: "#1a7f37"}
+
+	tests := map[ColorDepth]string{
+		ColorDepth16:   ColorGreen,
+		ColorDepth256:  "\033[38;5;28m",
+		ColorDepthTrue: "\033[38;2;26;127;55m",
+	}
+	for depth, want := range tests {
+		if got := c.resolve(depth); got != want {
+			t.Errorf("Depth %d: expected %q, got %q", depth, want, got)
+		}
+	}
+
+	if got := (ThemeColor{Basic: "red"}).resolve(ColorDepthTrue); got != ColorRed {
+		t.Errorf("Expected fallback to the basic color, got %q", got)
+	}
+	if got := (ThemeColor{Color256: "28"}).resolve(ColorDepth16); got != "" {
+		t.Errorf("Expected no color without a basic value at 16 colors, got %q", got)
+	}
+}
+
+func TestTheme_HexDownsampled(t *testing.T) {
+	c := ThemeColor{Basic: "#00af5f"}
+
+	tests := map[ColorDepth]string{
+		ColorDepth16:   ColorGreen,
+		ColorDepth256:  "\033[38;5;35m",
+		ColorDepthTrue: "\033[38;2;0;175;95m",
+	}
+	for depth, want := range tests {
+		if got := c.resolve(depth); got != want {
+			t.Errorf("Depth %d: expected %q, got %q", depth, want, got)
+		}
+	}
+	if got := (ThemeColor{Basic: "196"}).resolve(ColorDepth16); got != "\033[91m" {
+		t.Errorf("Expected a 256-color index brought down to bright red, got %q", got)
+	}
+}
+
+func TestTheme_PaletteSharedByEqualThemes(t *testing.T) {
+	build := func() *Theme {
+		custom := *ThemeLight
+		custom.Info = ThemeColor{Basic: "#00af5f"}
+		return &custom
+	}
+	a := NewCustomFormatter(WithTheme(build())).palette()
+	b := NewCustomFormatter(WithTheme(build())).palette()
+	if a != b {
+		t.Errorf("Expected themes with the same colors to share a palette")
+	}
+}
+
+func TestTheme_PresetsAreValid(t *testing.T) {
+	for _, name := range []string{"dark", "light", "solarized", "high-contrast", "monochrome"} {
+		theme, ok := LookupTheme(name)
+		if !ok {
+			t.Fatalf("Expected preset %q to be registered", name)
+		}
+		for role, field := range themeRoles {
+			c := field(theme)
+			for _, v := range []string{c.Basic, c.Color256, c.TrueColor} {
+				if v == "" {
+					continue
+				}
+				if _, err := ParseColor(v); err != nil {
+					t.Errorf("Theme %s, %s: %v", name, role, err)
+				}
+			}
+		}
+	}
+}
+
+func TestTheme_Monochrome(t *testing.T) {
+	f := NewCustomFormatter(WithTheme(ThemeMonochrome), WithNamespaceColumn(false, 0))
+	entry := logrus.NewEntry(logrus.New()).WithField("k", "v")
+	entry.Message = "[DB] ready"
+	entry.Level = logrus.InfoLevel
+
+	b, err := f.Format(entry)
+	if err != nil {
+		t.Fatalf("Format error: %v", err)
+	}
+	if strings.Contains(string(b), "\033[3") || strings.Contains(string(b), "\033[9") {
+		t.Errorf("Expected no foreground colors in monochrome, got %q", b)
+	}
+}
+
+func TestTheme_UserDefined(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+	custom := &Theme{Name: "test-custom", Info: ThemeColor{Basic: "blue"}}
+	RegisterTheme(custom)
+
+	logger := New(WithThemeName("Test-Custom"))
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	logger.Info("hello")
+
+	if !strings.Contains(buf.String(), "\033[34mINFO") {
+		t.Errorf("Expected blue INFO from the registered theme, got %q", buf.String())
+	}
+}
+
+func TestTheme_FromEnv(t *testing.T) {
+	t.Setenv("LOG_THEME", "light")
+
+	logger := New()
+	f, ok := logger.Formatter.(*CustomFormatter)
+	if !ok {
+		t.Fatalf("Expected CustomFormatter, got %T", logger.Formatter)
+	}
+	if f.Theme != ThemeLight {
+		t.Errorf("Expected light theme from LOG_THEME, got %v", f.Theme)
+	}
+
+	logger = New(WithThemeName("solarized"))
+	if f := logger.Formatter.(*CustomFormatter); f.Theme != ThemeSolarized {
+		t.Errorf("Expected WithThemeName to override LOG_THEME, got %v", f.Theme)
+	}
+}
+
+func TestWithThemeName_Unknown(t *testing.T) {
+	cfg := newConfig(WithThemeName("neon"))
+	if cfg.err == nil || !strings.Contains(cfg.err.Error(), `unknown theme "neon"`) {
+		t.Errorf("Expected unknown theme error, got %v", cfg.err)
+	}
+}
@@ -0,0 +1,61 @@
+package pretty
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// widthCondition measures runes the way most terminals draw them. Ambiguous
+// characters such as "•" count as one cell whatever the locale, so the output
+// does not depend on the environment of the process.
+var widthCondition = &runewidth.Condition{EastAsianWidth: false}
+
+// displayWidth returns the number of terminal cells s occupies. ANSI escapes
+// take no space; East Asian wide characters and emoji take two cells, combining
+// marks none. Grapheme clusters such as "👩‍💻" or flags count as one character.
+func displayWidth(s string) int {
+	s = stripANSI(s)
+
+	width := 0
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		width += clusterWidth(g.Runes())
+	}
+	return width
+}
+
+func clusterWidth(runes []rune) int {
+	w := 0
+	for _, r := range runes {
+		if w = widthCondition.RuneWidth(r); w > 0 {
+			break // The first visible rune decides, the rest are joiners and modifiers
+		}
+	}
+	if w == 0 {
+		return 0
+	}
+	for _, r := range runes {
+		switch {
+		case r == '\uFE0F': // Emoji presentation selector, e.g. "❤️"
+			return 2
+		case r >= 0x1F1E6 && r <= 0x1F1FF && len(runes) > 1: // Regional indicator pair: a flag
+			return 2
+		}
+	}
+	return w
+}
+
+// fillRun repeats fill to cover exactly width cells. When fill is wider than one
+// cell and does not divide width, the rest is made up with spaces.
+func fillRun(fill string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	fw := displayWidth(fill)
+	if fw <= 0 {
+		return strings.Repeat(" ", width)
+	}
+	return strings.Repeat(fill, width/fw) + strings.Repeat(" ", width%fw)
+}
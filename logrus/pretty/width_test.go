@@ -0,0 +1,82 @@
+package pretty
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"Auth", 4},
+		{"•", 1},
+		{"認証", 4},
+		{"데이터", 6},
+		{"café", 4},
+		{"cafe\u0301", 4}, // Combining acute accent
+		{"🚀", 2},
+		{"❤️", 2},
+		{"👩‍💻", 2},
+		{"🇩🇪", 2},
+		{ColorYellow + "[DB]" + ColorReset, 4},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.in); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFillRun(t *testing.T) {
+	tests := []struct {
+		fill  string
+		width int
+		want  string
+	}{
+		{"•", 3, "•••"},
+		{"─", 2, "──"},
+		{"＝", 5, "＝＝ "},
+		{"-", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := fillRun(tt.fill, tt.width); got != tt.want {
+			t.Errorf("fillRun(%q, %d) = %q, want %q", tt.fill, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestFormatter_WideTagsAlign(t *testing.T) {
+	tags := []string{"Auth", "認証", "🚀Go", "cafe\u0301", "❤️"}
+
+	for _, style := range []TagStyle{StyleDefault, StyleCenter, StyleRight} {
+		for _, colors := range []bool{false, true} {
+			for _, fill := range []string{"•", "＝"} {
+				f := NewCustomFormatter(WithColors(colors), WithTagStyle(style, fill), WithNamespaceColumn(true, 10))
+
+				col := -1
+				for _, tag := range tags {
+					entry := logrus.NewEntry(logrus.New()).WithField(NamespaceKey, "名前")
+					entry.Message = "[" + tag + "] message"
+					entry.Level = logrus.InfoLevel
+
+					b, err := f.Format(entry)
+					if err != nil {
+						t.Fatalf("Format error: %v", err)
+					}
+					line := stripANSI(string(b))
+					at := displayWidth(line[:strings.Index(line, "message")])
+					if col == -1 {
+						col = at
+					} else if at != col {
+						t.Errorf("style=%d colors=%v fill=%q: tag %q puts the message at cell %d, want %d:\n%s",
+							style, colors, fill, tag, at, col, line)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package pretty
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// LineWidthAuto sizes lines to the terminal, see CustomFormatter.LineWidth
+const LineWidthAuto = -1
+
+// minWrapWidth is the narrowest message column that is still wrapped
+const minWrapWidth = 20
+
+// OverflowMode defines what happens to lines longer than the line width
+type OverflowMode int
+
+const (
+	OverflowWrap     OverflowMode = iota // Wrap at word boundaries, continuing at the message column
+	OverflowTruncate                     // Drop the fields that don't fit, e.g. "user=bob …+3"
+)
+
+// overflowNames are the modes accepted by name in config files
+var overflowNames = map[string]OverflowMode{
+	"wrap":     OverflowWrap,
+	"truncate": OverflowTruncate,
+}
+
+// WithLineWidth limits lines to width cells, or the terminal width with
+// LineWidthAuto, and sets how longer lines are handled. Zero turns it off.
+// Only console output is affected; files always get whole lines.
+func WithLineWidth(width int, mode OverflowMode) FormatterOption {
+	return func(f *CustomFormatter) {
+		f.LineWidth = width
+		f.Overflow = mode
+	}
+}
+
+// isConsole reports whether w is the process's standard output or error
+func isConsole(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}
+
+// lineWidth resolves LineWidth, 0 meaning no limit
+func (f *CustomFormatter) lineWidth() int {
+	if f.LineWidth == LineWidthAuto {
+		return terminalWidth()
+	}
+	return max(0, f.LineWidth)
+}
+
+// widthRefresh is how long a measured terminal width is reused, so
+// formatting does not query the terminal for every entry
+const widthRefresh = time.Second
+
+// termWidth caches terminalWidth; expires is in Unix nanoseconds
+var termWidth struct {
+	width   atomic.Int64
+	expires atomic.Int64
+}
+
+// terminalWidth returns the width of the terminal on stdout or stderr, then
+// $COLUMNS, or 0 when neither is known. The result is measured at most once
+// per widthRefresh, so a resized terminal is picked up within a second.
+func terminalWidth() int {
+	now := time.Now().UnixNano()
+	if now < termWidth.expires.Load() {
+		return int(termWidth.width.Load())
+	}
+	w := measureTerminalWidth()
+	termWidth.width.Store(int64(w))
+	termWidth.expires.Store(now + int64(widthRefresh))
+	return w
+}
+
+func measureTerminalWidth() int {
+	for _, file := range []*os.File{os.Stdout, os.Stderr} {
+		if w, _, err := term.GetSize(int(file.Fd())); err == nil && w > 0 {
+			return w
+		}
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return 0
+}
+
+// writeBody writes the message, the fields and the inline caller, fitted to
+// the line width. column is where the message starts, indent is that many spaces.
+func (f *CustomFormatter) writeBody(b *strings.Builder, message string, fields []string, caller string, column int, indent string) {
+	width := f.lineWidth()
+	if width > 0 && width-column >= minWrapWidth && f.Overflow == OverflowTruncate {
+		// Whatever comes after the message has to fit on its last line
+		used := column + displayWidth(message)
+		if i := strings.LastIndexByte(message, '\n'); i >= 0 {
+			used = displayWidth(message[i+1:])
+		}
+		fields = f.fitFields(fields, width-used-displayWidth(caller))
+	}
+
+	var line strings.Builder
+	line.WriteString(message)
+	if len(fields) > 0 {
+		line.WriteByte(' ') // Lead with a space to separate from the message
+		line.WriteString(strings.Join(fields, " "))
+	}
+	line.WriteString(caller)
+
+	if width <= 0 || width-column < minWrapWidth || f.Overflow != OverflowWrap {
+		b.WriteString(line.String())
+		return
+	}
+	for i, l := range strings.Split(line.String(), "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+			column = 0 // Continuation lines of multi-line messages carry their own indent
+		}
+		wrapLine(b, l, column, width, indent)
+	}
+}
+
+// wrapLine writes line starting at column, breaking at spaces before width is
+// exceeded. Words longer than a line are left whole.
+func wrapLine(b *strings.Builder, line string, column, width int, indent string) {
+	col := column
+	start := column // Column of the first word on the current line
+	for i, word := range strings.Split(line, " ") {
+		w := displayWidth(word)
+		if i > 0 {
+			if w > 0 && col > start && col+1+w > width {
+				b.WriteString("\n" + indent)
+				col = len(indent)
+				start = col
+			} else {
+				b.WriteByte(' ')
+				col++
+			}
+		}
+		b.WriteString(word)
+		col += w
+	}
+}
+
+// fitFields keeps the leading fields that fit in avail cells, ending with a
+// marker for the number of hidden fields when some had to go
+func (f *CustomFormatter) fitFields(fields []string, avail int) []string {
+	used := 0
+	widths := make([]int, len(fields))
+	for i, field := range fields {
+		widths[i] = 1 + displayWidth(field) // Each field is preceded by a space
+		used += widths[i]
+	}
+	if used <= avail {
+		return fields
+	}
+
+	for n := len(fields) - 1; n >= 0; n-- {
+		used -= widths[n]
+		marker := "…+" + strconv.Itoa(len(fields)-n)
+		if used+1+displayWidth(marker) <= avail || n == 0 {
+			if f.UseColors {
+				marker = f.palette().caller + marker + ColorReset
+			}
+			return append(fields[:n:n], marker)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,98 @@
+package pretty
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/term"
+)
+
+func TestWrap_HangingIndent(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithLineWidth(50, OverflowWrap))
+	out := formatEntry(t, f, logrus.InfoLevel, "[HTTP] request finished after retrying the upstream", logrus.Fields{"status": 200, "path": "/api/users"})
+
+	indent := strings.Repeat(" ", 23)
+	want := "INFO   [HTTP]          request finished after\n" +
+		indent + "retrying the upstream\n" +
+		indent + "path=/api/users status=200\n"
+	if out != want {
+		t.Errorf("Expected wrapped lines at the message column\ngot:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestWrap_LongWordStaysWhole(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithLineWidth(50, OverflowWrap))
+	long := strings.Repeat("x", 40)
+	out := formatEntry(t, f, logrus.InfoLevel, "[HTTP] see "+long, nil)
+	if !strings.Contains(out, "\n"+strings.Repeat(" ", 23)+long+"\n") {
+		t.Errorf("Expected the long word on its own line, got:\n%s", out)
+	}
+}
+
+func TestWrap_Truncate(t *testing.T) {
+	f := NewCustomFormatter(WithColors(false), WithLineWidth(60, OverflowTruncate))
+	fields := logrus.Fields{"a": "1111111111", "b": "2222222222", "c": "3333333333", "d": "4"}
+	out := formatEntry(t, f, logrus.InfoLevel, "[DB] query", fields)
+
+	if out != "INFO   [DB]            query a=1111111111 b=2222222222 …+2\n" {
+		t.Errorf("Expected hidden fields counted, got %q", out)
+	}
+	if w := displayWidth(strings.TrimSuffix(out, "\n")); w > 60 {
+		t.Errorf("Expected at most 60 cells, got %d", w)
+	}
+
+	f.LineWidth = 200
+	if out := formatEntry(t, f, logrus.InfoLevel, "[DB] query", fields); strings.Contains(out, "…") {
+		t.Errorf("Expected all fields when they fit, got %q", out)
+	}
+}
+
+func TestWrap_AutoWidthFromColumns(t *testing.T) {
+	t.Setenv("COLUMNS", "45")
+	termWidth.expires.Store(0)
+	f := NewCustomFormatter(WithColors(false), WithLineWidth(LineWidthAuto, OverflowWrap))
+	out := formatEntry(t, f, logrus.InfoLevel, "[Job] one two three four five six seven", nil)
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if displayWidth(line) > 45 {
+			t.Errorf("Expected lines of at most 45 cells, got %q", line)
+		}
+	}
+}
+
+func TestTerminalWidth_Cached(t *testing.T) {
+	if term.IsTerminal(int(os.Stdout.Fd())) || term.IsTerminal(int(os.Stderr.Fd())) {
+		t.Skip("The terminal size wins over COLUMNS")
+	}
+	t.Setenv("COLUMNS", "80")
+	termWidth.expires.Store(0)
+	t.Cleanup(func() { termWidth.expires.Store(0) })
+	if w := terminalWidth(); w != 80 {
+		t.Fatalf("Expected 80 from COLUMNS, got %d", w)
+	}
+
+	t.Setenv("COLUMNS", "100")
+	if w := terminalWidth(); w != 80 {
+		t.Errorf("Expected the cached width until it expires, got %d", w)
+	}
+	termWidth.expires.Store(0)
+	if w := terminalWidth(); w != 100 {
+		t.Errorf("Expected the new width once expired, got %d", w)
+	}
+}
+
+func TestWrap_ConsoleOnly(t *testing.T) {
+	custom := NewCustomFormatter(WithColors(false), WithLineWidth(40, OverflowWrap))
+	logger := New(WithOutput(OutputFile), WithFile(filepath.Join(t.TempDir(), "app.log")), WithCustomFormat(*custom))
+	if f := logger.Formatter.(*CustomFormatter); f.LineWidth != 0 {
+		t.Errorf("Expected no line width for file output, got %d", f.LineWidth)
+	}
+
+	mw := NewMultiWriter(MultiWriterWithFormattersConfig{customFormat: custom})
+	mw.AddWriter(&strings.Builder{}, false, true)
+	if f := mw.pairs[0].f.(*CustomFormatter); f.LineWidth != 0 {
+		t.Errorf("Expected no line width for a non-console writer, got %d", f.LineWidth)
+	}
+}